@@ -1,25 +1,32 @@
 package sapphire
 
 import (
-	"fmt"
+	"errors"
 	"github.com/bwmarrin/discordgo"
+	"math"
+	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // ----- Argument casting -----
 
 // Argument represents an argument, it has methods to grab the right type.
 type Argument struct {
-	value    interface{}
-	provided bool
+	value     interface{}
+	provided  bool
+	isDefault bool
 }
 
 // The methods do not check for errors and casts rightaway, because such validations are done at argument parsing time
 // And the errors are reported and the command execution is aborted, so the type will always be the type the user asked for.
 // But it can panic if the usage is specifying a different type than what the user used, in that case it's their fault.
 
-// We need to cover as much as types as possible because it isn't easy for the user to extend these.
+// Types registered with bot.RegisterArgumentType can be retrieved with Value and type asserted to what the parser returns.
 
 // Returns the argument as a string.
 func (arg *Argument) AsString() string {
@@ -35,10 +42,16 @@ func (arg *Argument) AsFloat() float64 {
 }
 
 // IsProvided checks if this argument is provided, for optional arguments you must use this before casting.
+// Unless the argument has a default value, then it can always be casted.
 func (arg *Argument) IsProvided() bool {
 	return arg.provided
 }
 
+// IsDefault checks if this argument wasn't provided and holds the default value from the usage instead. e.g [days:int=7]
+func (arg *Argument) IsDefault() bool {
+	return arg.isDefault
+}
+
 func (arg *Argument) AsUser() *discordgo.User {
 	return arg.value.(*discordgo.User)
 }
@@ -63,8 +76,39 @@ func (arg *Argument) AsMessage() *discordgo.Message {
 	return arg.value.(*discordgo.Message)
 }
 
+func (arg *Argument) AsChannel() *discordgo.Channel {
+	return arg.value.(*discordgo.Channel)
+}
+
+func (arg *Argument) AsDuration() time.Duration {
+	return arg.value.(time.Duration)
+}
+
+func (arg *Argument) AsTime() time.Time {
+	return arg.value.(time.Time)
+}
+
+// AsEmoji returns the emoji, unicode emojis only have the Name set to the emoji itself.
+func (arg *Argument) AsEmoji() *discordgo.Emoji {
+	return arg.value.(*discordgo.Emoji)
+}
+
+func (arg *Argument) AsURL() *url.URL {
+	return arg.value.(*url.URL)
+}
+
+// Value returns the raw parsed value, this is how you get the values of custom argument types.
+// e.g ctx.Arg(0).Value().(*InventoryItem)
+func (arg *Argument) Value() interface{} {
+	return arg.value
+}
+
 // ----- Argument parsing -----
 
+// ArgumentParser parses raw into the value of an argument of the type described by tag.
+// The returned error is replied to the user and aborts the command execution.
+type ArgumentParser func(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error)
+
 // quick helper so i don't repeat provided:true
 func arg(val interface{}) *Argument {
 	return &Argument{provided: true, value: val}
@@ -76,69 +120,439 @@ var MentionRegex = regexp.MustCompile("^(?:<@!?)?(\\d{17,19})>?$")
 // The Regexp used for matching channel mentions.
 var ChannelMentionRegex = regexp.MustCompile("^(?:<#)?(\\d{17,19})>?$")
 
+// The Regexp used for matching custom emojis. e.g <:name:id> or <a:name:id> for animated ones.
+var CustomEmojiRegex = regexp.MustCompile("^<(a)?:(\\w{2,32}):(\\d{17,19})>$")
+
+// The Regexp used for matching raw IDs.
+var idRegex = regexp.MustCompile("^\\d{17,19}$")
+
+// The Regexp used for matching role mentions.
+var RoleMentionRegex = regexp.MustCompile("^(?:<@&)?(\\d{17,19})>?$")
+
+// The Regexp used for matching message IDs and jump links, the guild is @me for DMs.
+var MessageLinkRegex = regexp.MustCompile("^<?(?:https?://(?:(?:ptb|canary)\\.)?discord(?:app)?\\.com/channels/(\\d{17,19}|@me)/(\\d{17,19})/)?(\\d{17,19})>?$")
+
+// Argument types whose values can span multiple arguments, e.g 1 week or in 2 hours.
+// ParseArgs tries the longest span of arguments first for these.
+var multiWordTypes = map[string]bool{
+	"duration": true,
+	"time":     true,
+}
+
+// The names of the builtin argument types, ParseUsage uses it to tell unions from choices.
+var builtinTypeNames = builtinArgumentTypes()
+
+// Returns a fresh map of the builtin argument types, every bot gets it's own copy so registering types
+// on one bot doesn't leak into another.
+func builtinArgumentTypes() map[string]ArgumentParser {
+	return map[string]ArgumentParser{
+		"str":      parseString,
+		"string":   parseString,
+		"num":      parseInt,
+		"number":   parseInt,
+		"int":      parseInt,
+		"member":   parseMember,
+		"user":     parseUser,
+		"chan":     parseChannel,
+		"channel":  parseChannel,
+		"literal":  parseLiteral,
+		"float":    parseFloat,
+		"bool":     parseBool,
+		"role":     parseRole,
+		"guild":    parseGuild,
+		"server":   parseGuild,
+		"message":  parseMessage,
+		"msg":      parseMessage,
+		"duration": parseDurationArgument,
+		"time":     parseTimeArgument,
+		"emoji":    parseEmoji,
+		"url":      parseURL,
+	}
+}
+
 // Parses the raw argument as specified in tag in context of ctx
+// The choices and constraints of the tag are enforced here so they work for every type.
 func ParseArgument(ctx *CommandContext, tag *UsageTag, raw string) (*Argument, error) {
 	if raw == "" {
-		return &Argument{provided: false}, nil
-	}
-	switch tag.Type {
-	case "str":
-		fallthrough
-	case "string":
-		return arg(raw), nil
-	case "num":
-		fallthrough
-	case "number":
-		fallthrough
-	case "int":
-		val, err := strconv.Atoi(raw)
-		return arg(val), err
-	case "member":
-		match := MentionRegex.FindStringSubmatch(raw)
-		if len(match) < 2 {
-			return nil, fmt.Errorf("**%s** must be a valid member mention or ID.", tag.Name)
-		}
-		member := ctx.Member(match[1])
-		if member == nil {
-			return nil, fmt.Errorf("That member cannot be found in this server.")
+		if tag.Default == "" {
+			return &Argument{provided: false}, nil
+		}
+		// Not provided but we have a default, parse it like it was provided so it's in the right type.
+		def, err := ParseArgument(ctx, tag, tag.Default)
+		if err != nil {
+			return nil, err
 		}
-		return arg(member), nil
-	case "user":
-		match := MentionRegex.FindStringSubmatch(raw)
+		def.provided = false
+		def.isDefault = true
+		return def, nil
+	}
+	if len(tag.Types) > 0 {
+		return parseUnion(ctx, tag, raw)
+	}
+	parser, ok := ctx.Bot.ArgumentTypes[tag.Type]
+	if !ok {
+		return nil, errors.New(ctx.Localize("ARGUMENT_TYPE_INVALID", tag.Type))
+	}
+	if len(tag.Choices) > 0 {
+		choice := matchChoice(tag.Choices, raw)
+		if choice == "" {
+			return nil, errors.New(ctx.Localize("ARGUMENT_CHOICES", tag.Name, strings.Join(tag.Choices, ", ")))
+		}
+		raw = choice
+	}
+	val, err := parser(ctx, tag, raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkConstraints(ctx, tag, val); err != nil {
+		return nil, err
+	}
+	return arg(val), nil
+}
 
-		if len(match) < 2 {
-			return nil, fmt.Errorf("**%s** must be a valid user mention or ID.", tag.Name)
+// Tries the types of a union in order and returns the first that parses, if none does the error of the first is returned.
+// Errors that end prompts aren't tried further.
+func parseUnion(ctx *CommandContext, tag *UsageTag, raw string) (*Argument, error) {
+	var first error
+	for _, typ := range tag.Types {
+		alternative := *tag
+		alternative.Type = typ
+		alternative.Types = nil
+		arg, err := ParseArgument(ctx, &alternative, raw)
+		if err == nil {
+			return arg, nil
+		}
+		if _, abort := err.(*promptAbort); abort {
+			return nil, err
 		}
+		if first == nil {
+			first = err
+		}
+	}
+	return nil, first
+}
 
-		user, _ := ctx.FetchUser(match[1])
+// Returns the choice matching raw ignoring case or an empty string if none matches.
+func matchChoice(choices []string, raw string) string {
+	for _, choice := range choices {
+		if strings.EqualFold(choice, raw) {
+			return choice
+		}
+	}
+	return ""
+}
 
-		if user == nil {
-			return nil, fmt.Errorf("That user cannot be found.")
+// Checks the value against the tag's constraints, numbers are limited by their value and strings by their length.
+// Other types don't have anything to compare so constraints are ignored for them.
+func checkConstraints(ctx *CommandContext, tag *UsageTag, val interface{}) error {
+	if tag.Min == nil && tag.Max == nil {
+		return nil
+	}
+
+	var n float64
+	prefix := "ARGUMENT"
+	switch v := val.(type) {
+	case int:
+		n = float64(v)
+	case float64:
+		n = v
+	case string:
+		// Rest strings are limited by the length of all their words, see checkRestLength.
+		if tag.Rest {
+			return nil
 		}
+		n = float64(utf8.RuneCountInString(v))
+		prefix = "ARGUMENT_LENGTH"
+	default:
+		return nil
+	}
 
-		return arg(user), nil
-	case "chan":
-		fallthrough // Alias
-	case "channel":
-		match := ChannelMentionRegex.FindStringSubmatch(raw)
+	format := func(f *float64) string {
+		return strconv.FormatFloat(*f, 'f', -1, 64)
+	}
+	tooLow := tag.Min != nil && n < *tag.Min
+	tooHigh := tag.Max != nil && n > *tag.Max
+	if !tooLow && !tooHigh {
+		return nil
+	}
+	if tag.Min != nil && tag.Max != nil {
+		if *tag.Min == *tag.Max {
+			return errors.New(ctx.Localize(prefix+"_EXACT", tag.Name, format(tag.Min)))
+		}
+		return errors.New(ctx.Localize(prefix+"_RANGE", tag.Name, format(tag.Min), format(tag.Max)))
+	}
+	if tooLow {
+		return errors.New(ctx.Localize(prefix+"_MIN", tag.Name, format(tag.Min)))
+	}
+	return errors.New(ctx.Localize(prefix+"_MAX", tag.Name, format(tag.Max)))
+}
 
-		if len(match) < 2 {
-			return nil, fmt.Errorf("**%s** must be a valid channel mention or ID.", tag.Name)
+// Checks the length of the words of a rest string joined by spaces against the tag's constraints.
+func checkRestLength(ctx *CommandContext, tag *UsageTag, args []*Argument) error {
+	words := make([]string, 0, len(args))
+	for _, arg := range args {
+		word, ok := arg.value.(string)
+		if !ok {
+			return nil
 		}
+		words = append(words, word)
+	}
+	whole := *tag
+	whole.Rest = false
+	return checkConstraints(ctx, &whole, strings.Join(words, " "))
+}
+
+func parseString(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	return raw, nil
+}
 
-		channel, _ := ctx.Session.State.Channel(match[1])
+func parseInt(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	i, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, errors.New(ctx.Localize("ARGUMENT_INT", tag.Name))
+	}
+	return i, nil
+}
 
+// Members, users and channels can be given by mention, ID or name, see resolve.go for how names are matched.
+func parseMember(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	match := MentionRegex.FindStringSubmatch(raw)
+	if len(match) < 2 {
+		member, err := ctx.findMember(tag, raw)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil {
+			return nil, errors.New(ctx.Localize("ARGUMENT_MEMBER", tag.Name))
+		}
+		return member, nil
+	}
+	member := ctx.Member(match[1])
+	if member == nil {
+		return nil, errors.New(ctx.Localize("ARGUMENT_MEMBER_NOT_FOUND"))
+	}
+	return member, nil
+}
+
+func parseUser(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	match := MentionRegex.FindStringSubmatch(raw)
+
+	if len(match) < 2 {
+		user, err := ctx.findUser(tag, raw)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, errors.New(ctx.Localize("ARGUMENT_USER", tag.Name))
+		}
+		return user, nil
+	}
+
+	user, _ := ctx.FetchUser(match[1])
+
+	if user == nil {
+		return nil, errors.New(ctx.Localize("ARGUMENT_USER_NOT_FOUND"))
+	}
+
+	return user, nil
+}
+
+func parseChannel(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	match := ChannelMentionRegex.FindStringSubmatch(raw)
+
+	if len(match) < 2 {
+		channel, err := ctx.findChannel(tag, raw)
+		if err != nil {
+			return nil, err
+		}
 		if channel == nil {
-			return nil, fmt.Errorf("That channel cannot be found.")
+			return nil, errors.New(ctx.Localize("ARGUMENT_CHANNEL", tag.Name))
 		}
+		return channel, nil
+	}
 
-		return arg(channel), nil
-	case "literal":
-		if raw != tag.Name {
-			return nil, fmt.Errorf("Literal argument must be **%s**", tag.Name)
+	channel, _ := ctx.Session.State.Channel(match[1])
+
+	if channel == nil {
+		return nil, errors.New(ctx.Localize("ARGUMENT_CHANNEL_NOT_FOUND"))
+	}
+
+	return channel, nil
+}
+
+func parseLiteral(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	// Literals with choices were already matched by ParseArgument.
+	if raw != tag.Name && len(tag.Choices) == 0 {
+		return nil, errors.New(ctx.Localize("ARGUMENT_LITERAL", tag.Name))
+	}
+	return raw, nil
+}
+
+func parseFloat(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errors.New(ctx.Localize("ARGUMENT_FLOAT", tag.Name))
+	}
+	return f, nil
+}
+
+func parseBool(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	switch strings.ToLower(raw) {
+	case "yes", "on", "true":
+		return true, nil
+	case "no", "off", "false":
+		return false, nil
+	}
+	return nil, errors.New(ctx.Localize("ARGUMENT_BOOL", tag.Name))
+}
+
+// Roles can be given by mention, ID or name.
+func parseRole(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	if ctx.Guild == nil {
+		return nil, errors.New(ctx.Localize("ARGUMENT_GUILD_ONLY", tag.Name))
+	}
+
+	if match := RoleMentionRegex.FindStringSubmatch(raw); len(match) > 1 {
+		role, _ := ctx.Session.State.Role(ctx.Guild.ID, match[1])
+		if role == nil {
+			return nil, errors.New(ctx.Localize("ARGUMENT_ROLE_NOT_FOUND"))
 		}
-		return arg(raw), nil
-	default:
-		return nil, fmt.Errorf("The argument type '%s' is invalid.", tag.Type)
+		return role, nil
+	}
+
+	role, err := ctx.findRole(tag, raw)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New(ctx.Localize("ARGUMENT_ROLE", tag.Name))
+	}
+	return role, nil
+}
+
+// Guilds can be given by ID or their name ignoring case, only guilds the bot is in can be found.
+func parseGuild(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	if idRegex.MatchString(raw) {
+		guild, _ := ctx.Session.State.Guild(raw)
+		if guild == nil {
+			return nil, errors.New(ctx.Localize("ARGUMENT_GUILD_NOT_FOUND"))
+		}
+		return guild, nil
+	}
+
+	for _, guild := range ctx.Session.State.Guilds {
+		if strings.EqualFold(guild.Name, raw) {
+			return guild, nil
+		}
+	}
+
+	return nil, errors.New(ctx.Localize("ARGUMENT_GUILD", tag.Name))
+}
+
+// Messages can be given by ID in the current channel or by a jump link to a channel of the current guild the author can read.
+func parseMessage(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	match := MessageLinkRegex.FindStringSubmatch(raw)
+	if len(match) < 4 {
+		return nil, errors.New(ctx.Localize("ARGUMENT_MESSAGE", tag.Name))
+	}
+
+	channelID := ctx.Channel.ID
+	if match[2] != "" {
+		// Don't let links reach into other guilds or DMs, the author might not be able to see them.
+		guildID := ""
+		if ctx.Guild != nil {
+			guildID = ctx.Guild.ID
+		}
+		if (match[1] == "@me" && match[2] != ctx.Channel.ID) || (match[1] != "@me" && match[1] != guildID) {
+			return nil, errors.New(ctx.Localize("ARGUMENT_MESSAGE_NOT_FOUND"))
+		}
+		channelID = match[2]
+	}
+
+	// The link's guild is just text, the channel must really be in this guild and the author must be able to read it.
+	if channelID != ctx.Channel.ID {
+		channel, err := ctx.Session.State.Channel(channelID)
+		if err != nil {
+			channel, err = ctx.Session.Channel(channelID)
+		}
+		if err != nil || channel.GuildID != ctx.Guild.ID {
+			return nil, errors.New(ctx.Localize("ARGUMENT_MESSAGE_NOT_FOUND"))
+		}
+		member, err := ctx.FetchMember(ctx.Author.ID)
+		if err != nil || !PermissionsForMemberInChannel(ctx.Guild, channel, member).Has(discordgo.PermissionViewChannel|discordgo.PermissionReadMessageHistory) {
+			return nil, errors.New(ctx.Localize("ARGUMENT_MESSAGE_NOT_FOUND"))
+		}
+	}
+
+	// Try the cache first.
+	msg, err := ctx.Session.State.Message(channelID, match[3])
+	if err != nil {
+		msg, err = ctx.Session.ChannelMessage(channelID, match[3])
+		if err != nil {
+			return nil, errors.New(ctx.Localize("ARGUMENT_MESSAGE_NOT_FOUND"))
+		}
+	}
+
+	return msg, nil
+}
+
+func parseDurationArgument(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	d, ok := parseDuration(raw)
+	if !ok || d <= 0 {
+		return nil, errors.New(ctx.Localize("ARGUMENT_DURATION", tag.Name))
+	}
+	return d, nil
+}
+
+func parseTimeArgument(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	t, ok := parseTime(raw, time.Now())
+	if !ok {
+		return nil, errors.New(ctx.Localize("ARGUMENT_TIME", tag.Name))
+	}
+	return t, nil
+}
+
+// Emojis can be custom emojis or unicode emojis, custom ones aren't checked to exist since they can be from any guild.
+func parseEmoji(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	if match := CustomEmojiRegex.FindStringSubmatch(raw); match != nil {
+		return &discordgo.Emoji{ID: match[3], Name: match[2], Animated: match[1] != ""}, nil
+	}
+	if !isUnicodeEmoji(raw) {
+		return nil, errors.New(ctx.Localize("ARGUMENT_EMOJI", tag.Name))
+	}
+	return &discordgo.Emoji{Name: raw}, nil
+}
+
+// Checks if s is made of emoji characters only, this includes the joiners, skin tones and flags that form a single emoji.
+// It isn't a complete check against the emoji list but it's good enough to tell an emoji from text.
+func isUnicodeEmoji(s string) bool {
+	symbol := false
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r >= 0x1F1E6 && r <= 0x1F1FF, unicode.Is(unicode.So, r):
+			symbol = true
+		case r == 0x200D, r == 0xFE0F, r == 0x20E3, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F:
+			// Joiners, variation selectors, keycaps, skin tones and tags.
+		case (r >= '0' && r <= '9') || r == '#' || r == '*':
+			// Keycaps, e.g 1️⃣
+			if i+1 >= len(runes) || (runes[i+1] != 0xFE0F && runes[i+1] != 0x20E3) {
+				return false
+			}
+			symbol = true
+		default:
+			return false
+		}
+	}
+	return symbol
+}
+
+// URLs must be absolute http or https links, wrapping them in <> to hide the embed is allowed.
+func parseURL(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+	raw = strings.TrimSuffix(strings.TrimPrefix(raw, "<"), ">")
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New(ctx.Localize("ARGUMENT_URL", tag.Name))
 	}
+	return u, nil
 }
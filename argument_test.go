@@ -0,0 +1,200 @@
+package sapphire
+
+import (
+	"errors"
+	"github.com/bwmarrin/discordgo"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestBot(t *testing.T) *Bot {
+	s, err := discordgo.New("Bot token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(s)
+}
+
+func TestRegisterArgumentType(t *testing.T) {
+	bot := newTestBot(t)
+	type item struct{ name string }
+	bot.RegisterArgumentType("item", func(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+		if !strings.HasPrefix(raw, "item:") {
+			return nil, errors.New("not an item")
+		}
+		return &item{name: strings.TrimPrefix(raw, "item:")}, nil
+	})
+
+	cmd := NewCommand("use", "General", func(ctx *CommandContext) {}).SetUsage("<thing:item>")
+	bot.AddCommand(cmd)
+	ctx := &CommandContext{Bot: bot, Command: cmd}
+
+	arg, err := ParseArgument(ctx, cmd.Usage[0], "item:sword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := arg.Value().(*item); !ok || v.name != "sword" {
+		t.Errorf("Expected the custom value to be parsed but got %v", arg.Value())
+	}
+
+	if _, err := ParseArgument(ctx, cmd.Usage[0], "sword"); err == nil || err.Error() != "not an item" {
+		t.Errorf("Expected the parser error to be returned but got %v", err)
+	}
+}
+
+func TestAddCommandUnknownArgumentType(t *testing.T) {
+	bot := newTestBot(t)
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected AddCommand to panic on an unknown argument type")
+		}
+	}()
+	bot.AddCommand(NewCommand("use", "General", func(ctx *CommandContext) {}).SetUsage("<thing:item>"))
+}
+
+func TestArgumentConstraints(t *testing.T) {
+	bot := newTestBot(t)
+	cmd := NewCommand("give", "General", func(ctx *CommandContext) {}).SetUsage("<amount:int{1,100}> <name:string{3,}> <mode:on|off> [days:int=7]")
+	bot.AddCommand(cmd)
+	ctx := &CommandContext{Bot: bot, Command: cmd, Locale: English}
+
+	if _, err := ParseArgument(ctx, cmd.Usage[0], "101"); err == nil || err.Error() != "**amount** must be between 1 and 100." {
+		t.Errorf("Expected the range to be enforced but got %v", err)
+	}
+	if _, err := ParseArgument(ctx, cmd.Usage[1], "ab"); err == nil || err.Error() != "**name** must be at least 3 characters long." {
+		t.Errorf("Expected the length to be enforced but got %v", err)
+	}
+	if arg, err := ParseArgument(ctx, cmd.Usage[2], "ON"); err != nil || arg.AsString() != "on" {
+		t.Errorf("Expected the choice to match ignoring case but got %v %v", arg, err)
+	}
+	if _, err := ParseArgument(ctx, cmd.Usage[2], "maybe"); err == nil {
+		t.Error("Expected an invalid choice to fail")
+	}
+
+	arg, err := ParseArgument(ctx, cmd.Usage[3], "")
+	if err != nil || arg.IsProvided() || !arg.IsDefault() || arg.AsInt() != 7 {
+		t.Errorf("Expected the default value to be used but got %+v %v", arg, err)
+	}
+}
+
+func TestBuiltinArgumentTypes(t *testing.T) {
+	bot := newTestBot(t)
+	state := bot.Session.State
+	guild := &discordgo.Guild{ID: "200000000000000000", Name: "Test Server"}
+	state.GuildAdd(guild)
+	state.GuildAdd(&discordgo.Guild{ID: "210000000000000000", Name: "Other Server"})
+	state.RoleAdd(guild.ID, &discordgo.Role{ID: "300000000000000000", Name: "Moderators"})
+	channel := &discordgo.Channel{ID: "400000000000000000", GuildID: guild.ID, Type: discordgo.ChannelTypeGuildText}
+	state.ChannelAdd(channel)
+	state.MaxMessageCount = 10
+	state.MessageAdd(&discordgo.Message{ID: "500000000000000000", ChannelID: channel.ID, Content: "hello"})
+
+	cmd := NewCommand("test", "General", func(ctx *CommandContext) {}).SetUsage("<f:float> <b:bool> <r:role> <g:guild> <m:message> <c:channel>")
+	bot.AddCommand(cmd)
+	ctx := &CommandContext{Bot: bot, Command: cmd, Session: bot.Session, Guild: guild, Channel: channel, Locale: English}
+	parse := func(i int, raw string) *Argument {
+		arg, err := ParseArgument(ctx, cmd.Usage[i], raw)
+		if err != nil {
+			t.Errorf("Expected %q to parse as %s but got %v", raw, cmd.Usage[i].Type, err)
+			return &Argument{}
+		}
+		return arg
+	}
+
+	if f := parse(0, "1.5").AsFloat(); f != 1.5 {
+		t.Errorf("Expected 1.5 but got %v", f)
+	}
+	if !parse(1, "Yes").AsBool() || parse(1, "off").AsBool() {
+		t.Error("Expected yes to be true and off to be false")
+	}
+	if parse(2, "<@&300000000000000000>").AsRole().Name != "Moderators" || parse(2, "moderators").AsRole().ID != "300000000000000000" {
+		t.Error("Expected the role to be found by mention and name")
+	}
+	if parse(3, "other server").AsGuild().ID != "210000000000000000" {
+		t.Error("Expected the guild to be found by name")
+	}
+	if parse(4, "500000000000000000").AsMessage().Content != "hello" || parse(4, "<https://discord.com/channels/200000000000000000/400000000000000000/500000000000000000>").AsMessage().Content != "hello" {
+		t.Error("Expected the message to be found by ID and link")
+	}
+	if parse(5, "<#400000000000000000>").AsChannel() != channel {
+		t.Error("Expected the channel to be found by mention")
+	}
+
+	for i, raw := range []string{"NaN", "maybe", "Admins", "999999999999999999", "https://discord.com/channels/210000000000000000/400000000000000000/500000000000000000"} {
+		if _, err := ParseArgument(ctx, cmd.Usage[i], raw); err == nil {
+			t.Errorf("Expected %q to fail parsing as %s", raw, cmd.Usage[i].Type)
+		}
+	}
+}
+
+func TestMessageLinkAccess(t *testing.T) {
+	bot := newTestBot(t)
+	state := bot.Session.State
+	state.MaxMessageCount = 10
+	guild := &discordgo.Guild{ID: "200000000000000000", Roles: []*discordgo.Role{{ID: "200000000000000000", Permissions: discordgo.PermissionViewChannel | discordgo.PermissionReadMessageHistory}}}
+	state.GuildAdd(guild)
+	state.GuildAdd(&discordgo.Guild{ID: "210000000000000000"})
+	author := &discordgo.User{ID: "100000000000000000"}
+	state.MemberAdd(&discordgo.Member{GuildID: guild.ID, User: author})
+
+	channels := []*discordgo.Channel{
+		{ID: "400000000000000000", GuildID: guild.ID},
+		{ID: "410000000000000000", GuildID: guild.ID},
+		{ID: "420000000000000000", GuildID: guild.ID, PermissionOverwrites: []*discordgo.PermissionOverwrite{{ID: guild.ID, Deny: discordgo.PermissionViewChannel}}},
+		{ID: "430000000000000000", GuildID: "210000000000000000"},
+	}
+	for _, channel := range channels {
+		state.ChannelAdd(channel)
+		state.MessageAdd(&discordgo.Message{ID: "5" + channel.ID[1:], ChannelID: channel.ID, Content: "hello"})
+	}
+
+	cmd := NewCommand("quote", "General", func(ctx *CommandContext) {}).SetUsage("<m:message>")
+	bot.AddCommand(cmd)
+	ctx := &CommandContext{Bot: bot, Command: cmd, Session: bot.Session, Guild: guild, Channel: channels[0], Author: author, Locale: English}
+	link := func(channel *discordgo.Channel) string {
+		return "https://discord.com/channels/" + guild.ID + "/" + channel.ID + "/5" + channel.ID[1:]
+	}
+
+	if _, err := ParseArgument(ctx, cmd.Usage[0], link(channels[1])); err != nil {
+		t.Errorf("Expected a readable channel of the guild to work but got %v", err)
+	}
+	// A hidden channel and a channel of another guild behind this guild's ID.
+	for _, channel := range channels[2:] {
+		if _, err := ParseArgument(ctx, cmd.Usage[0], link(channel)); err == nil {
+			t.Errorf("Expected the message in %s to not be found", channel.ID)
+		}
+	}
+}
+
+func TestMultiWordArguments(t *testing.T) {
+	bot := newTestBot(t)
+	cmd := NewCommand("remind", "General", func(ctx *CommandContext) {}).SetUsage("<length:duration> <emoji:emoji> [link:url] [note:string...]")
+	bot.AddCommand(cmd)
+	ctx := &CommandContext{Bot: bot, Command: cmd, Locale: English, RawArgs: Tokenize("1 week 2 days 👍🏽 <https://example.com/a> take out the trash")}
+
+	if !ctx.ParseArgs() {
+		t.Fatal("Expected the arguments to parse")
+	}
+	if d := ctx.Arg(0).AsDuration(); d != 9*24*time.Hour {
+		t.Errorf("Expected the duration to take 4 arguments but got %v", d)
+	}
+	if e := ctx.Arg(1).AsEmoji(); e.Name != "👍🏽" || e.ID != "" {
+		t.Errorf("Expected a unicode emoji but got %+v", e)
+	}
+	if u := ctx.Arg(2).AsURL(); u.Host != "example.com" {
+		t.Errorf("Expected the link to be parsed but got %v", u)
+	}
+	if s := ctx.Arg(3).AsString(); s != "take" || len(ctx.Args) != 7 {
+		t.Errorf("Expected the rest to start after the link but got %q", s)
+	}
+
+	for i, raw := range []string{"soon", "hello", "ftp://example.com"} {
+		if _, err := ParseArgument(ctx, cmd.Usage[i], raw); err == nil {
+			t.Errorf("Expected %q to fail parsing as %s", raw, cmd.Usage[i].Type)
+		}
+	}
+	if e, err := ParseArgument(ctx, cmd.Usage[1], "<a:party:123456789012345678>"); err != nil || !e.AsEmoji().Animated || e.AsEmoji().Name != "party" {
+		t.Errorf("Expected a custom emoji but got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package sapphire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AuditKind is the kind of problem an audit found.
+type AuditKind string
+
+const (
+	AuditMissing   AuditKind = "missing"   // The language doesn't have a key the default language has.
+	AuditExtra     AuditKind = "extra"     // The language has a key the default language doesn't.
+	AuditFormat    AuditKind = "format"    // The format verbs or placeholders don't match the default language.
+	AuditUndefined AuditKind = "undefined" // The source uses a key that no language has.
+)
+
+// AuditIssue is a problem with a translation found by an audit.
+type AuditIssue struct {
+	Kind     AuditKind `json:"kind"`
+	Language string    `json:"language,omitempty"`
+	Key      string    `json:"key"`
+	Message  string    `json:"message"`
+	Position string    `json:"position,omitempty"` // file:line:column of the key for undefined keys.
+}
+
+func (i AuditIssue) String() string {
+	if i.Kind == AuditUndefined {
+		return fmt.Sprintf("%s: %s %s", i.Position, i.Key, i.Message)
+	}
+	return fmt.Sprintf("%s: %s %s", i.Language, i.Key, i.Message)
+}
+
+// LocaleKey is a key literal passed to a localization method in the source.
+type LocaleKey struct {
+	Key      string
+	Position string // file:line:column of the key.
+}
+
+// AuditLanguage diffs lang against the reference language reporting the missing and extra keys and the keys
+// whose format verbs don't match, the issues are sorted by key.
+func AuditLanguage(lang, reference *Language) []AuditIssue {
+	return auditLanguage(lang, reference, []*Language{lang})
+}
+
+// Diffs lang against reference, a key is only missing if none of the languages in chain have it.
+func auditLanguage(lang, reference *Language, chain []*Language) []AuditIssue {
+	var issues []AuditIssue
+	for key := range reference.Keys {
+		found := false
+		for _, l := range chain {
+			if _, ok := l.Keys[key]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			issues = append(issues, AuditIssue{Kind: AuditMissing, Language: lang.Name, Key: key,
+				Message: fmt.Sprintf("is missing from %s.", reference.Name)})
+		}
+	}
+	for key, value := range lang.Keys {
+		want, ok := reference.Keys[key]
+		if !ok {
+			issues = append(issues, AuditIssue{Kind: AuditExtra, Language: lang.Name, Key: key,
+				Message: fmt.Sprintf("isn't in %s.", reference.Name)})
+		}
+		if err := checkKey(key, value, want); err != nil {
+			issues = append(issues, AuditIssue{Kind: AuditFormat, Language: lang.Name, Key: key, Message: err.Error()})
+		}
+	}
+	sortIssues(issues)
+	return issues
+}
+
+// AuditLanguages diffs every registered language against the default locale.
+// Regional variants aren't reported missing keys that their chain has, e.g es-MX doesn't need the keys es has.
+func (bot *Bot) AuditLanguages() []AuditIssue {
+	names := make([]string, 0, len(bot.Languages))
+	for name := range bot.Languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []AuditIssue
+	for _, name := range names {
+		lang := bot.Languages[name]
+		if lang == bot.DefaultLocale {
+			continue
+		}
+		issues = append(issues, auditLanguage(lang, bot.DefaultLocale, bot.auditChain(lang))...)
+	}
+	return issues
+}
+
+// Returns the languages that lang falls back to before the default locales, including lang.
+func (bot *Bot) auditChain(lang *Language) []*Language {
+	defaults := map[*Language]bool{bot.DefaultLocale: true}
+	for _, name := range bot.DefaultLocales {
+		defaults[bot.GetLanguage(name)] = true
+	}
+	chain := []*Language{lang}
+	for _, l := range bot.LocaleChain(lang.Name) {
+		if defaults[l] {
+			break
+		}
+		if l != lang {
+			chain = append(chain, l)
+		}
+	}
+	return chain
+}
+
+// AuditKeys reports the keys that none of the languages have.
+func AuditKeys(keys []LocaleKey, languages ...*Language) []AuditIssue {
+	var issues []AuditIssue
+	for _, key := range keys {
+		found := false
+		for _, lang := range languages {
+			if _, ok := lang.Keys[key.Key]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			issues = append(issues, AuditIssue{Kind: AuditUndefined, Key: key.Key, Position: key.Position,
+				Message: "isn't defined in any language."})
+		}
+	}
+	return issues
+}
+
+// Methods taking a locale key and the index of the key argument.
+var localeMethods = map[string]int{
+	"ReplyLocale": 0,
+	"EditLocale":  1,
+	"Localize":    0,
+}
+
+// SourceErrors are the go files FindLocaleKeys couldn't parse.
+type SourceErrors []error
+
+func (e SourceErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// FindLocaleKeys finds the string literal keys passed to ReplyLocale, EditLocale and Localize in the go files of fsys.
+// Keys that aren't literals can't be known and are skipped, so are vendor and testdata directories.
+// Files that can't be parsed are skipped too and returned as SourceErrors along with the keys of the other files.
+func FindLocaleKeys(fsys fs.FS) ([]LocaleKey, error) {
+	var keys []LocaleKey
+	var invalid SourceErrors
+	fset := gotoken.NewFileSet()
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != "." && (d.Name() == "vendor" || d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".")) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(file) != ".go" {
+			return nil
+		}
+		src, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		f, err := parser.ParseFile(fset, file, src, 0)
+		if err != nil {
+			invalid = append(invalid, err)
+			return nil
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			arg, ok := localeMethods[sel.Sel.Name]
+			if !ok || len(call.Args) <= arg {
+				return true
+			}
+			lit, ok := call.Args[arg].(*ast.BasicLit)
+			if !ok || lit.Kind != gotoken.STRING {
+				return true
+			}
+			if key, err := strconv.Unquote(lit.Value); err == nil {
+				keys = append(keys, LocaleKey{Key: key, Position: fset.Position(lit.Pos()).String()})
+			}
+			return true
+		})
+		return nil
+	})
+	if err == nil && len(invalid) > 0 {
+		err = invalid
+	}
+	return keys, err
+}
+
+func sortIssues(issues []AuditIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Key != issues[j].Key {
+			return issues[i].Key < issues[j].Key
+		}
+		return issues[i].Kind < issues[j].Kind
+	})
+}
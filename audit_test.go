@@ -0,0 +1,88 @@
+package sapphire
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func issueSet(issues []AuditIssue) map[string]AuditKind {
+	set := make(map[string]AuditKind)
+	for _, issue := range issues {
+		set[issue.Language+":"+issue.Key] = issue.Kind
+	}
+	return set
+}
+
+func TestAuditLanguages(t *testing.T) {
+	bot := newTestBot(t)
+	bot.AddLanguage(NewLanguage("base").Set("HELLO", "Hello %s").Set("BYE", "Bye"))
+	bot.SetDefaultLocale("base")
+	bot.AddLanguage(NewLanguage("es").Set("HELLO", "Hola %d").Set("EXTRA", "Extra"))
+	bot.AddLanguage(NewLanguage("es-MX").Set("BYE", "Nos vemos"))
+	bot.AddLanguage(NewLanguage("fr").Set("HELLO", "{name, select, other {Salut {name}}").Set("BYE", "Salut"))
+
+	expected := map[string]AuditKind{
+		"es:HELLO":  AuditFormat,
+		"es:EXTRA":  AuditExtra,
+		"es:BYE":    AuditMissing,
+		"fr:HELLO":  AuditFormat,
+		"en-US:BYE": AuditMissing,
+	}
+	got := issueSet(bot.AuditLanguages())
+	for key, kind := range expected {
+		if got[key] != kind {
+			t.Errorf("Expected %s to be %s but got %q", key, kind, got[key])
+		}
+	}
+	// es-MX gets HELLO from es and the builtin English keys are all extra.
+	if kind, ok := got["es-MX:HELLO"]; ok {
+		t.Errorf("Expected es-MX:HELLO to fallback to es but got %s", kind)
+	}
+	if got["en-US:COMMAND_PING"] != AuditExtra {
+		t.Errorf("Expected en-US:COMMAND_PING to be extra but got %q", got["en-US:COMMAND_PING"])
+	}
+}
+
+func TestFindLocaleKeys(t *testing.T) {
+	fsys := fstest.MapFS{
+		"commands/ping.go": {Data: []byte(`package commands
+
+func Ping(ctx *sapphire.CommandContext) {
+	ctx.ReplyLocale("COMMAND_PING")
+	msg, _ := ctx.ReplyLocale(key)
+	ctx.EditLocale(msg, "NOT_DEFINED", 1)
+	ctx.Reply(ctx.Localize(` + "`COMMAND_ERROR`" + `))
+}
+`)},
+		"vendor/lib/lib.go": {Data: []byte(`package lib
+
+func f() { ctx.ReplyLocale("VENDORED") }
+`)},
+		"README.md":          {Data: []byte(`ctx.ReplyLocale("MARKDOWN")`)},
+		"commands/broken.go": {Data: []byte(`package commands; func {`)},
+	}
+
+	// The broken file is reported without stopping the scan.
+	keys, err := FindLocaleKeys(fsys)
+	if errs, ok := err.(SourceErrors); !ok || len(errs) != 1 || !strings.Contains(errs.Error(), "broken.go") {
+		t.Fatalf("Expected the broken file to be reported but got %v", err)
+	}
+	expected := []string{"COMMAND_PING", "NOT_DEFINED", "COMMAND_ERROR"}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, keys)
+	}
+	for i, key := range keys {
+		if key.Key != expected[i] {
+			t.Errorf("Expected %s but got %s", expected[i], key.Key)
+		}
+	}
+	if keys[1].Position != "commands/ping.go:6:22" {
+		t.Errorf("Expected the position commands/ping.go:6:22 but got %s", keys[1].Position)
+	}
+
+	issues := AuditKeys(keys, English)
+	if len(issues) != 1 || issues[0].Key != "NOT_DEFINED" || issues[0].Kind != AuditUndefined {
+		t.Errorf("Expected NOT_DEFINED to be undefined but got %v", issues)
+	}
+}
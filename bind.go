@@ -0,0 +1,220 @@
+package sapphire
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Bind fills the struct pointed to by v from the parsed arguments and flags, fields are matched by their struct tags:
+// arg:"name" is the argument with that name in the usage, rest arguments fill slices.
+// flag:"name" is the flag with that name, e.g --days=7
+// default:"value" is used when the argument or flag isn't given.
+// e.g with the field Target *discordgo.Member `arg:"target"` and the field Days int `flag:"days" default:"0"`
+// if err := ctx.Bind(&args); err != nil { ctx.Reply("%s", err) }
+// Values are converted to the type of the field, e.g an int argument can fill an int64 field and flags are parsed from their text.
+// Errors about values the user gave are localized so they can be replied as is.
+func (ctx *CommandContext) Bind(v interface{}) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Struct {
+		return errors.New("Bind needs a pointer to a struct.")
+	}
+	val := ptr.Elem()
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		argName, isArg := field.Tag.Lookup("arg")
+		flagName, isFlag := field.Tag.Lookup("flag")
+		if !isArg && !isFlag {
+			continue
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("The field %s is unexported and can't be bound.", field.Name)
+		}
+		def, hasDefault := field.Tag.Lookup("default")
+
+		var err error
+		var set bool
+		if isArg {
+			set, err = ctx.bindArgument(val.Field(i), argName)
+		} else {
+			set, err = ctx.bindFlag(val.Field(i), flagName)
+		}
+		if err != nil {
+			return err
+		}
+		if !set && hasDefault {
+			if err := setFieldString(val.Field(i), def); err != nil {
+				return fmt.Errorf("The default of the field %s is invalid: %v", field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Sets field to the argument called name, returns false if the argument wasn't provided.
+func (ctx *CommandContext) bindArgument(field reflect.Value, name string) (bool, error) {
+	for i, tag := range ctx.Command.Usage {
+		if !strings.EqualFold(tag.Name, name) {
+			continue
+		}
+		var args []*Argument
+		if i < len(ctx.Args) {
+			args = ctx.Args[i:]
+			if !tag.Rest {
+				args = args[:1]
+			}
+		}
+		if len(args) == 0 || (!args[0].provided && !args[0].isDefault) {
+			return false, nil
+		}
+
+		if tag.Rest && field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(field.Type(), len(args), len(args))
+			for j, arg := range args {
+				if !setFieldValue(slice.Index(j), arg.value) {
+					return false, fmt.Errorf("The argument %s can't be bound to a %s.", name, field.Type())
+				}
+			}
+			field.Set(slice)
+			return true, nil
+		}
+
+		// A rest of strings bound to a single string is joined with spaces.
+		if tag.Rest && len(args) > 1 && field.Kind() == reflect.String {
+			words := make([]string, len(args))
+			for j, arg := range args {
+				word, ok := arg.value.(string)
+				if !ok {
+					return false, fmt.Errorf("The argument %s can't be bound to a %s.", name, field.Type())
+				}
+				words[j] = word
+			}
+			field.SetString(strings.Join(words, " "))
+			return true, nil
+		}
+
+		if !setFieldValue(field, args[0].value) {
+			return false, fmt.Errorf("The argument %s can't be bound to a %s.", name, field.Type())
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("The usage has no argument called %s.", name)
+}
+
+// Sets field to the flag called name, returns false if the flag wasn't given.
+// Declared flags are already parsed, other flags are converted from their text.
+func (ctx *CommandContext) bindFlag(field reflect.Value, name string) (bool, error) {
+	if a, ok := ctx.flagArgs[name]; ok {
+		if !a.provided && !a.isDefault {
+			return false, nil
+		}
+		if !setFieldValue(field, a.value) {
+			return false, fmt.Errorf("The flag %s can't be bound to a %s.", name, field.Type())
+		}
+		return true, nil
+	}
+	raw, ok := ctx.Flags[name]
+	if !ok {
+		return false, nil
+	}
+	// A flag without a value like --force has it's name as the value.
+	if field.Kind() == reflect.Bool && raw == name {
+		field.SetBool(true)
+		return true, nil
+	}
+	if err := setFieldString(field, raw); err != nil {
+		return false, errors.New(ctx.Localize("FLAG_INVALID", name, friendlyTypeName(field.Type())))
+	}
+	return true, nil
+}
+
+// Sets field to an already parsed value, converting between numbers if needed.
+func setFieldValue(field reflect.Value, value interface{}) bool {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return false
+	}
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return true
+	}
+	if isNumber(v.Kind()) && isNumber(field.Kind()) && v.Type().ConvertibleTo(field.Type()) {
+		field.Set(v.Convert(field.Type()))
+		return true
+	}
+	return false
+}
+
+// Sets field by parsing raw as the field's type.
+func setFieldString(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		d, ok := parseDuration(raw)
+		if !ok {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		switch strings.ToLower(raw) {
+		case "yes", "on", "true":
+			field.SetBool(true)
+		case "no", "off", "false":
+			field.SetBool(false)
+		default:
+			return fmt.Errorf("invalid bool %q", raw)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("can't parse a %s from text", field.Type())
+	}
+	return nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return (kind >= reflect.Int && kind <= reflect.Uint64) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// The name of a type as the user would understand it, used in errors.
+func friendlyTypeName(typ reflect.Type) string {
+	if typ == durationType {
+		return "duration"
+	}
+	switch {
+	case typ.Kind() == reflect.Bool:
+		return "bool"
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		return "number"
+	case isNumber(typ.Kind()):
+		return "whole number"
+	}
+	return typ.String()
+}
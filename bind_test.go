@@ -0,0 +1,64 @@
+package sapphire
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBind(t *testing.T) {
+	bot := newTestBot(t)
+	cmd := NewCommand("ban", "Moderation", func(ctx *CommandContext) {}).SetUsage("<count:int> [days:int=7] <reason:string...>")
+	bot.AddCommand(cmd)
+	ctx := &CommandContext{
+		Bot:     bot,
+		Command: cmd,
+		Locale:  English,
+		RawArgs: Tokenize("3 being mean"),
+		Flags:   map[string]string{"silent": "silent", "delay": "1h", "limit": "10"},
+	}
+	if !ctx.ParseArgs() {
+		t.Fatal("Expected the arguments to parse")
+	}
+
+	var args struct {
+		Count   int64         `arg:"count"`
+		Days    int           `arg:"days"`
+		Reason  string        `arg:"reason"`
+		Words   []string      `arg:"reason"`
+		Silent  bool          `flag:"silent"`
+		Delay   time.Duration `flag:"delay"`
+		Limit   uint          `flag:"limit"`
+		Missing float64       `flag:"missing" default:"1.5"`
+		Ignored string
+	}
+	if err := ctx.Bind(&args); err != nil {
+		t.Fatal(err)
+	}
+	if args.Count != 3 || args.Days != 7 || args.Reason != "being mean" || len(args.Words) != 2 {
+		t.Errorf("Expected the arguments to be bound but got %+v", args)
+	}
+	if !args.Silent || args.Delay != time.Hour || args.Limit != 10 || args.Missing != 1.5 {
+		t.Errorf("Expected the flags to be bound but got %+v", args)
+	}
+
+	ctx.Flags["limit"] = "lots"
+	if err := ctx.Bind(&args); err == nil || err.Error() != "The flag **limit** must be a valid whole number." {
+		t.Errorf("Expected an invalid flag to be reported but got %v", err)
+	}
+
+	var wrong struct {
+		Count string `arg:"count"`
+	}
+	if err := ctx.Bind(&wrong); err == nil {
+		t.Error("Expected binding an int to a string to fail")
+	}
+	var unknown struct {
+		Target string `arg:"target"`
+	}
+	if err := ctx.Bind(&unknown); err == nil {
+		t.Error("Expected binding an unknown argument to fail")
+	}
+	if err := ctx.Bind(args); err == nil {
+		t.Error("Expected binding a non pointer to fail")
+	}
+}
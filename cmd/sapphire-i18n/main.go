@@ -0,0 +1,105 @@
+// Command sapphire-i18n audits the translations of a sapphire bot.
+//
+// It diffs the language files in a directory against the default language and reports missing keys, extra keys
+// and format verbs that don't match, then reports the ReplyLocale, EditLocale and Localize key literals in the source that no language defines.
+// It exits with 1 if there are any problems so it can fail a CI job.
+//
+//	$ sapphire-i18n -languages languages -src .
+//	$ sapphire-i18n -languages languages -default es,en-US -json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sapphire-cord/sapphire"
+	"os"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("languages", "", "The directory with the language files.")
+	defaults := flag.String("default", "en-US", "The default locales separated by commas.")
+	src := flag.String("src", "", "The source directory to find undefined keys in.")
+	asJSON := flag.Bool("json", false, "Output the issues as json.")
+	flag.Parse()
+
+	issues, skipped, err := audit(*dir, strings.Split(*defaults, ","), *src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	// Files that can't be parsed don't stop the audit but their keys are unknown.
+	for _, err := range skipped {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if *asJSON {
+		if issues == nil {
+			issues = []sapphire.AuditIssue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(issues)
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) == 0 && len(skipped) == 0 {
+			fmt.Println("No problems found.")
+		}
+	}
+
+	if len(issues) > 0 || len(skipped) > 0 {
+		os.Exit(1)
+	}
+}
+
+// Audits the languages in dir against the defaults and the keys used in src, returns the issues and the source files that couldn't be parsed.
+func audit(dir string, defaults []string, src string) ([]sapphire.AuditIssue, sapphire.SourceErrors, error) {
+	// The session is never opened, the bot is only used for it's languages.
+	s, err := discordgo.New("Bot")
+	if err != nil {
+		return nil, nil, err
+	}
+	bot := sapphire.New(s)
+
+	if dir != "" {
+		languages, err := sapphire.DecodeLanguagesFS(os.DirFS(dir), ".")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, lang := range languages {
+			// A file for the builtin language overrides some of it's keys.
+			if lang.Name == sapphire.English.Name {
+				lang = sapphire.NewLanguage(lang.Name).Merge(sapphire.English).Merge(lang)
+			}
+			bot.AddLanguage(lang)
+		}
+	}
+
+	for _, locale := range defaults {
+		if bot.GetLanguage(locale) == nil {
+			return nil, nil, fmt.Errorf("The default language '%s' cannot be found.", locale)
+		}
+	}
+	bot.SetDefaultLocale(defaults[0], defaults[1:]...)
+
+	issues := bot.AuditLanguages()
+	var skipped sapphire.SourceErrors
+	if src != "" {
+		keys, err := sapphire.FindLocaleKeys(os.DirFS(src))
+		if errs, ok := err.(sapphire.SourceErrors); ok {
+			skipped = errs
+		} else if err != nil {
+			return nil, nil, err
+		}
+		languages := make([]*sapphire.Language, 0, len(bot.Languages))
+		for _, lang := range bot.Languages {
+			languages = append(languages, lang)
+		}
+		issues = append(issues, sapphire.AuditKeys(keys, languages...)...)
+	}
+	return issues, skipped, nil
+}
@@ -0,0 +1,153 @@
+package sapphire
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"sync"
+	"time"
+)
+
+// Collectors wait for a message or a reaction matching a filter, the message is handed to the first collector that wants it
+// instead of running the monitors on it, so an answer to a question isn't also ran as a command.
+
+// A pending collector, events are either a *discordgo.Message or a *discordgo.MessageReaction
+type collector struct {
+	filter  func(event interface{}) bool
+	ch      chan interface{}
+	tracked bool // Whether the waiting goroutine is counted as busy in the bot's activity.
+}
+
+// Tracks how many monitors are busy, a monitor waiting on a collector isn't busy.
+// HandleMessage waits for this to reach zero so it doesn't wait forever on a command that is waiting for a reply.
+type activity struct {
+	lock sync.Mutex
+	cond *sync.Cond
+	busy int
+}
+
+func newActivity() *activity {
+	a := &activity{}
+	a.cond = sync.NewCond(&a.lock)
+	return a
+}
+
+func (a *activity) add(n int) {
+	a.lock.Lock()
+	a.busy += n
+	if a.busy <= 0 {
+		a.cond.Broadcast()
+	}
+	a.lock.Unlock()
+}
+
+// Blocks until no monitor is busy.
+func (a *activity) wait() {
+	a.lock.Lock()
+	for a.busy > 0 {
+		a.cond.Wait()
+	}
+	a.lock.Unlock()
+}
+
+// AwaitMessage waits for the next message in channelID sent by userID and returns it, or nil if timeout passes first.
+// The message is consumed, monitors and commands don't run on it.
+// Inside commands use ctx.AwaitReply instead.
+func (bot *Bot) AwaitMessage(channelID, userID string, timeout time.Duration) *discordgo.Message {
+	return bot.awaitMessage(channelID, userID, timeout, false)
+}
+
+func (bot *Bot) awaitMessage(channelID, userID string, timeout time.Duration, tracked bool) *discordgo.Message {
+	event := bot.await(func(event interface{}) bool {
+		m, ok := event.(*discordgo.Message)
+		return ok && m.ChannelID == channelID && m.Author.ID == userID
+	}, timeout, tracked)
+	if event == nil {
+		return nil
+	}
+	return event.(*discordgo.Message)
+}
+
+// Waits for userID to react with emoji on the message with messageID, returns false if timeout passes first.
+func (bot *Bot) awaitReaction(messageID, userID, emoji string, timeout time.Duration, tracked bool) bool {
+	return bot.await(func(event interface{}) bool {
+		r, ok := event.(*discordgo.MessageReaction)
+		return ok && r.MessageID == messageID && r.UserID == userID && r.Emoji.Name == emoji
+	}, timeout, tracked) != nil
+}
+
+// Waits for the first event matching filter, returns nil if timeout passes first.
+func (bot *Bot) await(filter func(event interface{}) bool, timeout time.Duration, tracked bool) interface{} {
+	c := &collector{
+		filter:  filter,
+		ch:      make(chan interface{}, 1),
+		tracked: tracked,
+	}
+
+	bot.collectorsLock.Lock()
+	bot.collectors = append(bot.collectors, c)
+	bot.collectorsLock.Unlock()
+
+	if tracked {
+		bot.activity.add(-1)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case event := <-c.ch:
+		return event
+	case <-timer.C:
+		if bot.removeCollector(c) {
+			if tracked {
+				bot.activity.add(1)
+			}
+			return nil
+		}
+		// A message was handed to us right as we timed out.
+		return <-c.ch
+	}
+}
+
+// Removes c and returns true if it was still pending.
+func (bot *Bot) removeCollector(c *collector) bool {
+	bot.collectorsLock.Lock()
+	defer bot.collectorsLock.Unlock()
+	for i, pending := range bot.collectors {
+		if pending == c {
+			bot.collectors = append(bot.collectors[:i], bot.collectors[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Hands event to the first collector that wants it and returns true if one took it.
+func (bot *Bot) collect(event interface{}) bool {
+	bot.collectorsLock.Lock()
+	defer bot.collectorsLock.Unlock()
+	for i, c := range bot.collectors {
+		if c.filter(event) {
+			bot.collectors = append(bot.collectors[:i], bot.collectors[i+1:]...)
+			// The waiting monitor is busy again from now on so HandleMessage waits for it too.
+			if c.tracked {
+				bot.activity.add(1)
+			}
+			c.ch <- event
+			return true
+		}
+	}
+	return false
+}
+
+// HandleReaction hands r to whoever is waiting for it as if it was received from discord and waits for them to finish.
+// This is meant for driving the bot without a connection, e.g in tests, see the sapphiretest package.
+func (bot *Bot) HandleReaction(r *discordgo.MessageReaction) {
+	bot.collect(r)
+	bot.activity.wait()
+}
+
+func reactionListener(bot *Bot) func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	return func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		bot.collect(r.MessageReaction)
+	}
+}
@@ -5,26 +5,36 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"io"
 	"strings"
+	"sync"
+	"time"
 )
 
 type CommandHandler func(ctx *CommandContext)
 
 // Command represents a command in the sapphire framework.
 type Command struct {
-	Name                string         // The command's name. (default: required)
-	Aliases             []string       // Aliases that point to this command. (default: [])
-	Run                 CommandHandler // The handler that actually runs the command. (default: required)
-	Enabled             bool           // Wether this command is enabled. (default: true)
-	Description         string         // The command's brief description. (default: "No Description Provided.")
-	Category            string         // The category this command belongs to. (default: required)
-	OwnerOnly           bool           // Wether this command can only be used by the owner. (default: false)
-	GuildOnly           bool           // Wether this command can only be ran on a guild. (default: false)
-	UsageString         string         // Usage string for this command. (default: "")
-	Usage               []*UsageTag    // Parsed usage tags for this command.
-	Cooldown            int            // Command cooldown in seconds. (default: 0)
-	Editable            bool           // Wether this command's response will be editable. (default: true)
-	RequiredPermissions int            // Permissions the user needs to run this command. (default: 0)
-	BotPermissions      int            // Permissions the bot needs to perform this command. (default: 0)
+	Name                string              // The command's name. (default: required)
+	Aliases             []string            // Aliases that point to this command. (default: [])
+	Run                 CommandHandler      // The handler that actually runs the command. (default: required)
+	Enabled             bool                // Wether this command is enabled, use Enable and Disable once the bot runs. (default: true)
+	Description         string              // The command's brief description. (default: "No Description Provided.")
+	Category            string              // The category this command belongs to. (default: required)
+	OwnerOnly           bool                // Wether this command can only be used by the owner. (default: false)
+	GuildOnly           bool                // Wether this command can only be ran on a guild. (default: false)
+	UsageString         string              // Usage string for this command. (default: "")
+	Usage               []*UsageTag         // Parsed usage tags for this command.
+	Cooldown            int                 // Command cooldown in seconds, per user. (default: 0)
+	CooldownBucket      *CooldownBucket     // Cooldown with a scope and uses per window, overrides Cooldown. (default: nil)
+	Editable            bool                // Wether this command's response will be editable. (default: true)
+	RequiredPermissions int64               // Permissions the user needs to run this command. (default: 0)
+	BotPermissions      int64               // Permissions the bot needs to perform this command. (default: 0)
+	Subcommands         map[string]*Command // Map of subcommands, e.g create in !tag create (default: {})
+	Parent              *Command            // The command this is a subcommand of, nil for top level commands.
+	Prompting           bool                // Wether to ask for missing or invalid arguments instead of failing. (default: false)
+	Flags               []*Flag             // The declared flags, see AddFlag. (default: [])
+	ArgDescriptions     map[string]string   // Descriptions of the arguments by name for application commands. (default: {})
+	subaliases          map[string]string
+	enabledLock         sync.RWMutex // Enabled is toggled by the enable and disable builtins while commands run.
 }
 
 func NewCommand(name string, category string, run CommandHandler) *Command {
@@ -40,9 +50,13 @@ func NewCommand(name string, category string, run CommandHandler) *Command {
 		UsageString:         "",
 		Editable:            true,
 		Cooldown:            0,
+		CooldownBucket:      nil,
 		RequiredPermissions: 0,
 		BotPermissions:      0,
 		Usage:               make([]*UsageTag, 0),
+		Subcommands:         make(map[string]*Command),
+		ArgDescriptions:     make(map[string]string),
+		subaliases:          make(map[string]string),
 	}
 }
 
@@ -52,6 +66,92 @@ func (c *Command) AddAliases(aliases ...string) *Command {
 	return c
 }
 
+// AddSubcommand adds sub as a subcommand of this command.
+// e.g with a tag command and a create subcommand !tag create <name> runs create with the rest of the arguments.
+// If the subcommand has no category it inherits this command's category.
+// A command that is only a group for subcommands can be created with a nil CommandHandler.
+func (c *Command) AddSubcommand(sub *Command) *Command {
+	existing, ok := c.Subcommands[sub.Name]
+	// Same as bot.AddCommand, unload the aliases of the subcommand we are overriding.
+	if ok {
+		for _, a := range existing.Aliases {
+			delete(c.subaliases, a)
+		}
+	}
+	if sub.Category == "" {
+		sub.Category = c.Category
+	}
+	sub.Parent = c
+	c.Subcommands[sub.Name] = sub
+	for _, alias := range sub.Aliases {
+		c.subaliases[alias] = sub.Name
+	}
+	return c
+}
+
+// GetSubcommand returns a subcommand by name, it also searches by aliases, returns nil if not found.
+func (c *Command) GetSubcommand(name string) *Command {
+	cmd, ok := c.Subcommands[name]
+	if ok {
+		return cmd
+	}
+	alias, ok := c.subaliases[name]
+	if ok {
+		return c.Subcommands[alias]
+	}
+	return nil
+}
+
+// FullName returns the name of the command prefixed by the names of it's parents, e.g "tag create"
+func (c *Command) FullName() string {
+	if c.Parent == nil {
+		return c.Name
+	}
+	return c.Parent.FullName() + " " + c.Name
+}
+
+// IsEnabled checks if this command and all of it's parents are enabled.
+// Disabling a command also disables all of it's subcommands.
+func (c *Command) IsEnabled() bool {
+	if c.Parent == nil {
+		return c.enabled()
+	}
+	return c.enabled() && c.Parent.IsEnabled()
+}
+
+// Returns Enabled of this command alone.
+func (c *Command) enabled() bool {
+	c.enabledLock.RLock()
+	defer c.enabledLock.RUnlock()
+	return c.Enabled
+}
+
+// IsOwnerOnly returns true if this command or any of it's parents is owner only.
+func (c *Command) IsOwnerOnly() bool {
+	return c.OwnerOnly || (c.Parent != nil && c.Parent.IsOwnerOnly())
+}
+
+// IsGuildOnly returns true if this command or any of it's parents is guild only.
+func (c *Command) IsGuildOnly() bool {
+	return c.GuildOnly || (c.Parent != nil && c.Parent.IsGuildOnly())
+}
+
+// Returns the permissions the user needs for this command and all of it's parents.
+func (c *Command) requiredPermissions() int64 {
+	if c.Parent == nil {
+		return c.RequiredPermissions
+	}
+	return c.RequiredPermissions | c.Parent.requiredPermissions()
+}
+
+// Returns the permissions the bot needs for this command and all of it's parents.
+func (c *Command) botPermissions() int64 {
+	if c.Parent == nil {
+		return c.BotPermissions
+	}
+	return c.BotPermissions | c.Parent.botPermissions()
+}
+
 // SetDescription sets the command's description
 func (c *Command) SetDescription(description string) *Command {
 	c.Description = description
@@ -72,13 +172,17 @@ func (c *Command) SetUsage(usage string) *Command {
 
 // Disable disables the command.
 func (c *Command) Disable() *Command {
+	c.enabledLock.Lock()
 	c.Enabled = false
+	c.enabledLock.Unlock()
 	return c
 }
 
 // Enable enables the command.
 func (c *Command) Enable() *Command {
+	c.enabledLock.Lock()
 	c.Enabled = true
+	c.enabledLock.Unlock()
 	return c
 }
 
@@ -100,27 +204,86 @@ func (c *Command) SetEditable(toggle bool) *Command {
 	return c
 }
 
-// SetCooldown sets the command's cooldown in seconds.
+// SetPrompting toggles wether the author is asked for missing or invalid arguments one by one instead of the command failing.
+// The bot waits for the author's next message in the channel for each of them, up to bot.PromptTimeout.
+func (c *Command) SetPrompting(toggle bool) *Command {
+	c.Prompting = toggle
+	return c
+}
+
+// SetArgDescription sets the description of the argument called name, application commands show it for the option.
+// Arguments without one are described by their name.
+func (c *Command) SetArgDescription(name, description string) *Command {
+	c.ArgDescriptions[name] = description
+	return c
+}
+
+// SetCooldown sets the command's cooldown in seconds, every user can use the command once per cooldown.
+// Use SetCooldownBucket for more control.
 func (c *Command) SetCooldown(cooldown int) *Command {
 	c.Cooldown = cooldown
+	c.CooldownBucket = nil
+	return c
+}
+
+// SetCooldownBucket sets the command's cooldown to allow uses per window shared by scope.
+// e.g SetCooldownBucket(CooldownChannel, 3, 10*time.Second) allows 3 uses every 10 seconds in each channel.
+// A window of 0 removes the cooldown.
+func (c *Command) SetCooldownBucket(scope CooldownScope, uses int, window time.Duration) *Command {
+	c.Cooldown = 0
+	if window <= 0 || uses <= 0 {
+		c.CooldownBucket = nil
+		return c
+	}
+	c.CooldownBucket = &CooldownBucket{Scope: scope, Uses: uses, Window: window}
+	return c
+}
+
+// Returns the bucket of the command's cooldown, nil if it has none.
+func (c *Command) cooldownBucket() *CooldownBucket {
+	if c.CooldownBucket != nil {
+		return c.CooldownBucket
+	}
+	if c.Cooldown > 0 {
+		return &CooldownBucket{Scope: CooldownUser, Uses: 1, Window: time.Duration(c.Cooldown) * time.Second}
+	}
+	return nil
+}
+
+// SetRequiredPermissions sets the permissions the user needs to run this command.
+func (c *Command) SetRequiredPermissions(bits int64) *Command {
+	c.RequiredPermissions = bits
+	return c
+}
+
+// SetBotPermissions sets the permissions the bot needs to perform this command.
+func (c *Command) SetBotPermissions(bits int64) *Command {
+	c.BotPermissions = bits
 	return c
 }
 
 // CommandContext represents an execution context of a command.
 type CommandContext struct {
-	Command     *Command           // The currently executing command.
-	Message     *discordgo.Message // The message of this command.
-	Session     *discordgo.Session // The discordgo session.
-	Bot         *Bot               // The sapphire Bot.
-	Channel     *discordgo.Channel // The channel this command was ran on.
-	Author      *discordgo.User    // Alias of Context.Message.Author
-	Args        []*Argument        // List of arguments.
-	Prefix      string             // The prefix used to invoke this command.
-	Guild       *discordgo.Guild   // The guild this command was ran on.
-	Flags       map[string]string  // Map of flags passed to the command. e.g --flag=yo
-	Locale      *Language          // The current language.
-	RawArgs     []string           // The raw args that may not match the usage string.
-	InvokedName string             // The name this command was invoked as, this includes the used alias.
+	Command      *Command               // The currently executing command.
+	Message      *discordgo.Message     // The message of this command.
+	Session      *discordgo.Session     // The discordgo session.
+	Bot          *Bot                   // The sapphire Bot.
+	Channel      *discordgo.Channel     // The channel this command was ran on.
+	Author       *discordgo.User        // Alias of Context.Message.Author
+	Args         []*Argument            // List of arguments.
+	Prefix       string                 // The prefix used to invoke this command.
+	Guild        *discordgo.Guild       // The guild this command was ran on.
+	Flags        map[string]string      // Map of flags passed to the command. e.g --flag=yo
+	Locale       *Language              // The current language.
+	RawArgs      []string               // The raw args that may not match the usage string.
+	InvokedName  string                 // The name this command was invoked as, this includes the used alias.
+	Interaction  *discordgo.Interaction // The interaction if this command was invoked as an application command.
+	responseID   string
+	argContent   string  // The content the raw arguments were tokenized from.
+	argTokens    []token // The tokens of the raw arguments, used to recover the original spacing.
+	flagArgs     map[string]*Argument
+	unknownFlags []string
+	cooldown     *CooldownBucket // The bucket to take a use from once the arguments are parsed, set by CooldownInhibitor.
 }
 
 // CommandError represents a panic that occured during a command execution.
@@ -153,13 +316,17 @@ func (ctx *CommandContext) Reply(content string, args ...interface{}) (*discordg
 		content = fmt.Sprintf(content, args...)
 	}
 
-	m, ok := ctx.Bot.CommandEdits[ctx.Message.ID]
+	if ctx.Interaction != nil {
+		return ctx.respond(&content, nil, true)
+	}
+
+	m, ok := ctx.Bot.CommandEdits.Get(ctx.Message.ID)
 	if !ok {
 		msg, err := ctx.Session.ChannelMessageSend(ctx.Channel.ID, content)
 		if err != nil {
 			return nil, err
 		}
-		ctx.Bot.CommandEdits[ctx.Message.ID] = msg.ID
+		ctx.Bot.CommandEdits.Set(ctx.Message.ID, msg.ID, ctx.Bot.EditWindow)
 		return msg, nil
 	}
 	return ctx.Session.ChannelMessageEditComplex(discordgo.NewMessageEdit(ctx.Channel.ID, m).
@@ -172,44 +339,55 @@ func (ctx *CommandContext) ReplyNoEdit(content string, args ...interface{}) (*di
 	if len(args) > 0 {
 		content = fmt.Sprintf(content, args...)
 	}
+	if ctx.Interaction != nil {
+		return ctx.respond(&content, nil, false)
+	}
 	return ctx.Session.ChannelMessageSend(ctx.Channel.ID, content)
 }
 
-// ReplyLocale sends a localized key for the current context's locale.
-func (ctx *CommandContext) ReplyLocale(key string, args ...interface{}) (*discordgo.Message, error) {
-	res := ctx.Locale.Get(key, args...)
+// Localize returns the localized string for key in the current context's locale.
+// If the key isn't found it tries the rest of the locale's chain, e.g pt-BR then pt then the default locales,
+// and if it is still not found it returns an error message about the missing key.
+func (ctx *CommandContext) Localize(key string, args ...interface{}) string {
+	chain := ctx.localeChain()
+	for _, lang := range chain {
+		if res := lang.Get(key, args...); res != "" {
+			return res
+		}
+	}
 
-	if res != "" {
-		return ctx.Reply(res)
+	// All failed, the key isn't translated, report the error.
+	// We have to also watch out if the error message isn't translated!
+	for _, lang := range chain {
+		if res := lang.Get("LOCALE_NO_KEY", key); res != "" {
+			return res
+		}
 	}
+	return fmt.Sprintf("No localization found for the key \"%s\" Please report this to the developers.", key)
+}
 
-	// Try the default locale.
-	fallback := ctx.Bot.DefaultLocale.Get(key, args...)
-	if fallback != "" {
-		return ctx.Reply(fallback)
+// Returns the languages to look up keys in, the context's locale first even if it isn't registered.
+func (ctx *CommandContext) localeChain() []*Language {
+	if ctx.Locale == nil {
+		return ctx.Bot.LocaleChain("")
+	}
+	chain := []*Language{ctx.Locale}
+	for _, lang := range ctx.Bot.LocaleChain(ctx.Locale.Name) {
+		if lang != ctx.Locale {
+			chain = append(chain, lang)
+		}
 	}
+	return chain
+}
 
-	// All failed, the key isn't translated, report the error.
-	// We have to also watch out if the error message isn't translated!
-	return ctx.Reply(ctx.Locale.GetDefault("LOCALE_NO_KEY", key,
-		ctx.Bot.DefaultLocale.GetDefault("LOCALE_NO_KEY", key,
-			fmt.Sprintf("No localization found for the key \"%s\" Please report this to the developers.", key))))
+// ReplyLocale sends a localized key for the current context's locale.
+func (ctx *CommandContext) ReplyLocale(key string, args ...interface{}) (*discordgo.Message, error) {
+	return ctx.Reply(ctx.Localize(key, args...))
 }
 
 // EditLocale edits msg with a localized key
 func (ctx *CommandContext) EditLocale(msg *discordgo.Message, key string, args ...interface{}) (*discordgo.Message, error) {
-	res := ctx.Locale.Get(key, args...)
-	if res != "" {
-		return ctx.Edit(msg, res)
-	}
-	fallback := ctx.Bot.DefaultLocale.Get(key, args...)
-	if fallback != "" {
-		return ctx.Edit(msg, fallback)
-	}
-	// All failed, the key isn't translated, report the error.                                                                              // We have to also watch out if the error message isn't translated!
-	return ctx.Edit(msg, ctx.Locale.GetDefault("LOCALE_NO_KEY", key,
-		ctx.Bot.DefaultLocale.GetDefault("LOCALE_NO_KEY", key,
-			fmt.Sprintf("No localization found for the key \"%s\" Please report this to the developers.", key))))
+	return ctx.Edit(msg, ctx.Localize(key, args...))
 }
 
 // Edit edits msg's content
@@ -219,6 +397,13 @@ func (ctx *CommandContext) Edit(msg *discordgo.Message, content string, args ...
 	if len(args) > 0 {
 		content = fmt.Sprintf(content, args...)
 	}
+	// Interaction responses are webhook messages, they have to be edited through the interaction.
+	if ctx.Interaction != nil {
+		if msg.ID == ctx.responseID {
+			return ctx.Session.InteractionResponseEdit(ctx.Interaction, &discordgo.WebhookEdit{Content: &content})
+		}
+		return ctx.Session.FollowupMessageEdit(ctx.Interaction, msg.ID, &discordgo.WebhookEdit{Content: &content})
+	}
 	return ctx.Session.ChannelMessageEdit(msg.ChannelID, msg.ID, content)
 }
 
@@ -232,13 +417,17 @@ func (ctx *CommandContext) ReplyEmbed(embed *discordgo.MessageEmbed) (*discordgo
 	if !ctx.Command.Editable {
 		return ctx.ReplyEmbedNoEdit(embed)
 	}
-	m, ok := ctx.Bot.CommandEdits[ctx.Message.ID]
+	if ctx.Interaction != nil {
+		content := ""
+		return ctx.respond(&content, []*discordgo.MessageEmbed{embed}, true)
+	}
+	m, ok := ctx.Bot.CommandEdits.Get(ctx.Message.ID)
 	if !ok {
 		msg, err := ctx.Session.ChannelMessageSendEmbed(ctx.Channel.ID, embed)
 		if err != nil {
 			return nil, err
 		}
-		ctx.Bot.CommandEdits[ctx.Message.ID] = msg.ID
+		ctx.Bot.CommandEdits.Set(ctx.Message.ID, msg.ID, ctx.Bot.EditWindow)
 		return msg, nil
 	}
 	return ctx.Session.ChannelMessageEditComplex(discordgo.NewMessageEdit(ctx.Channel.ID, m).SetContent("").SetEmbed(embed))
@@ -246,6 +435,9 @@ func (ctx *CommandContext) ReplyEmbed(embed *discordgo.MessageEmbed) (*discordgo
 
 // ReplyEmbedNoEdits replies with an embed but not considering the editable option of the command.
 func (ctx *CommandContext) ReplyEmbedNoEdit(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	if ctx.Interaction != nil {
+		return ctx.respond(nil, []*discordgo.MessageEmbed{embed}, false)
+	}
 	return ctx.Session.ChannelMessageSendEmbed(ctx.Channel.ID, embed)
 }
 
@@ -310,11 +502,18 @@ func (ctx *CommandContext) Arg(idx int) *Argument {
 // This uses the raw arguments so arguments of different types are also shown in their raw form.
 // This also means invalid arguments are also included but strings can never be invalid so this is useful
 // for getting the rest strings.
+// The text is sliced from the original content so quotes, newlines and spacing are kept as typed.
 func (ctx *CommandContext) JoinedArgs(sliced ...int) string {
 	var s int = 0
 	if len(sliced) > 0 {
 		s = sliced[0]
 	}
+	if s >= len(ctx.RawArgs) {
+		return ""
+	}
+	if len(ctx.argTokens) == len(ctx.RawArgs) {
+		return strings.TrimSpace(ctx.argContent[ctx.argTokens[s].start:])
+	}
 	return strings.Join(ctx.RawArgs[s:], " ")
 }
 
@@ -322,65 +521,149 @@ func (ctx *CommandContext) JoinedArgs(sliced ...int) string {
 // This is called in the command handler to process the arguments, it shouldn't be used in normal code
 // It is exported to allow modification of the command handler in your own bot and avoid this line from giving errors.
 func (ctx *CommandContext) ParseArgs() bool {
-	// Helper to get an index without panicking.
-	safeGet := func(idx int) string {
-		if len(ctx.RawArgs) > idx {
-			return ctx.RawArgs[idx]
-		}
-		return ""
-	}
-
 	// If it doesn't need arguments we are done.
 	if ctx.Command.UsageString == "" {
 		return true
 	}
 
-	ctx.Args = make([]*Argument, len(ctx.Command.Usage))
+	m := newArgumentMatcher(ctx)
+	for {
+		args, ok := m.match(0, 0)
+		if ok {
+			ctx.Args = args
+			return true
+		}
+		if m.aborted != nil {
+			ctx.Reply("%s", m.aborted.Error())
+			return false
+		}
 
-	for i, tag := range ctx.Command.Usage {
-		v := safeGet(i)
+		failure := m.failure
+		tag := ctx.Command.Usage[failure.tag]
+		// A required argument can't be skipped so it's ambiguous name is asked about, optionals report it.
+		if ambiguous, ok := failure.err.(*ambiguousName); ok && tag.Required {
+			value, err := ctx.chooseName(tag, ambiguous)
+			if err != nil {
+				ctx.Reply("%s", err.Error())
+				return false
+			}
+			ctx.spliceRawArgs(failure.pos, failure.n, []string{strings.Join(ctx.RawArgs[failure.pos:failure.pos+failure.n], " ")})
+			m.answer(failure.tag, failure.pos, []*Argument{arg(value)})
+			continue
+		}
+		if !ctx.Command.Prompting {
+			if failure.err == nil {
+				ctx.ReplyLocale("ARGUMENT_REQUIRED", tag.Name)
+			} else {
+				ctx.Reply("%s", failure.err.Error())
+			}
+			return false
+		}
 
-		if tag.Required && v == "" {
-			ctx.Reply("The argument **%s** is required.", tag.Name)
+		question := ctx.Localize("PROMPT_ARGUMENT", tag.Name)
+		if failure.err != nil {
+			question = ctx.Localize("PROMPT_RETRY", failure.err.Error())
+		}
+		args, values, ok := ctx.promptArgument(tag, question)
+		if !ok {
 			return false
 		}
+		// The answer takes the place of what failed and we match again.
+		ctx.spliceRawArgs(failure.pos, failure.n, values)
+		m.answer(failure.tag, failure.pos, args)
+	}
+}
+
+// Replaces n raw arguments at pos with the values answered to a prompt so JoinedArgs includes them.
+// The original spacing is lost so JoinedArgs joins them with spaces from now on.
+func (ctx *CommandContext) spliceRawArgs(pos, n int, values []string) {
+	raw := append([]string{}, ctx.RawArgs[:pos]...)
+	raw = append(raw, values...)
+	ctx.RawArgs = append(raw, ctx.RawArgs[pos+n:]...)
+	ctx.argTokens = nil
+}
+
+// An error that ends a prompt, e.g it timed out or the author cancelled, it shouldn't be asked again.
+type promptAbort struct {
+	message string
+}
 
+func (err *promptAbort) Error() string {
+	return err.message
+}
+
+// Asks the author for the argument described by tag until their answer parses, rest arguments take every word of the answer.
+// Returns the parsed arguments and their raw values or false if the author cancelled or didn't answer in time,
+// the reason is already replied.
+func (ctx *CommandContext) promptArgument(tag *UsageTag, question string) ([]*Argument, []string, bool) {
+	for {
+		ctx.Reply("%s", question)
+
+		answer := ctx.AwaitReply(ctx.Bot.PromptTimeout)
+		if answer == nil {
+			ctx.Reply("%s", ctx.Localize("PROMPT_TIMEOUT"))
+			return nil, nil, false
+		}
+		content := strings.TrimSpace(answer.Content)
+		if strings.EqualFold(content, ctx.Localize("PROMPT_CANCEL_KEYWORD")) {
+			ctx.Reply("%s", ctx.Localize("PROMPT_CANCELLED"))
+			return nil, nil, false
+		}
+
+		values := []string{content}
 		if tag.Rest {
-			cut := ctx.RawArgs[i:]
-
-			for ii, raw := range cut {
-				arg, err := ParseArgument(ctx, tag, raw)
-
-				if err != nil {
-					ctx.Reply(err.Error())
-					return false
-				}
-
-				// This ugly test is because we allocate a slice of n where n = amount of usage tags.
-				// So imagine <test:string> <another:string...> that's 2
-				// We hit rest and we don't have enough size to set that index directly. (because another can hold infinite args)
-				// So we use append, however we gotta be careful the <another:string...> tag also got it's dedicated index allocated
-				// So we do an ugly check: if we are on that index then we can just set directly otherwise we append.
-				// This could eventually be cleaned up for a better solution.
-				if ii > len(ctx.Args)-2 {
-					ctx.Args = append(ctx.Args, arg)
-				} else {
-					ctx.Args[i] = arg
-				}
-			}
-		} else {
-			arg, err := ParseArgument(ctx, tag, safeGet(i))
+			values = Tokenize(content)
+		}
 
-			if err != nil {
-				ctx.Reply(err.Error())
-				return false
+		args := make([]*Argument, 0, len(values))
+		var err error
+		for _, value := range values {
+			var arg *Argument
+			if arg, err = ctx.parseAnswer(tag, value); err != nil {
+				break
 			}
+			args = append(args, arg)
+		}
+		if err == nil && tag.Rest {
+			err = checkRestLength(ctx, tag, args)
+		}
 
-			ctx.Args[i] = arg
+		if _, aborted := err.(*promptAbort); aborted {
+			ctx.Reply("%s", err.Error())
+			return nil, nil, false
+		}
+		if err != nil {
+			question = ctx.Localize("PROMPT_RETRY", err.Error())
+			continue
+		}
+		if len(args) == 0 || !args[0].provided {
+			question = ctx.Localize("PROMPT_ARGUMENT", tag.Name)
+			continue
 		}
+		return args, values, true
 	}
+}
 
-	return true
+// Parses a value answered to a prompt, the argument is known so an ambiguous name is asked about right away.
+func (ctx *CommandContext) parseAnswer(tag *UsageTag, raw string) (*Argument, error) {
+	parsed, err := ParseArgument(ctx, tag, raw)
+	if ambiguous, ok := err.(*ambiguousName); ok {
+		value, err := ctx.chooseName(tag, ambiguous)
+		if err != nil {
+			return nil, err
+		}
+		return arg(value), nil
+	}
+	return parsed, err
+}
+
+// AwaitReply waits for the author's next message in this channel and returns it, or nil if timeout passes first.
+// The message is consumed so it doesn't run any commands. e.g
+// ctx.Reply("Are you sure? (yes/no)")
+// if answer := ctx.AwaitReply(30 * time.Second); answer != nil && answer.Content == "yes" { ... }
+func (ctx *CommandContext) AwaitReply(timeout time.Duration) *discordgo.Message {
+	// Commands invoked by messages run in a monitor, tell the bot it's not busy while waiting.
+	return ctx.Bot.awaitMessage(ctx.Channel.ID, ctx.Author.ID, timeout, ctx.Interaction == nil)
 }
 
 // User gets a user by id, returns nil if not found.
@@ -418,6 +701,23 @@ func (ctx *CommandContext) Member(id string) *discordgo.Member {
 	return member
 }
 
+// FetchMember searches the cache for the given member id in the current guild and if not found, attempts to fetch it from the API.
+func (ctx *CommandContext) FetchMember(id string) (*discordgo.Member, error) {
+	if ctx.Guild == nil {
+		return nil, fmt.Errorf("Cannot fetch a member outside of a guild.")
+	}
+
+	// Try the cache first.
+	member := ctx.Member(id)
+
+	if member != nil {
+		return member, nil
+	}
+
+	// Call the API.
+	return ctx.Session.GuildMember(ctx.Guild.ID, id)
+}
+
 // GetFirstMentionedUser returns the first user mentioned in the message.
 func (ctx *CommandContext) GetFirstMentionedUser() *discordgo.User {
 	if len(ctx.Message.Mentions) < 1 {
@@ -434,6 +734,10 @@ func (ctx *CommandContext) CodeBlock(lang, content string, args ...interface{})
 }
 
 // React adds the reaction emoji to the message that triggered the command.
+// Application commands have no message to react to so it returns an error for them.
 func (ctx *CommandContext) React(emoji string) error {
+	if ctx.Interaction != nil {
+		return fmt.Errorf("Cannot react to an application command.")
+	}
 	return ctx.Session.MessageReactionAdd(ctx.Channel.ID, ctx.Message.ID, emoji)
 }
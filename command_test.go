@@ -0,0 +1,31 @@
+package sapphire
+
+import (
+	"testing"
+)
+
+func TestSubcommands(t *testing.T) {
+	noop := func(ctx *CommandContext) {}
+	create := NewCommand("create", "", noop).SetUsage("<name:string> <content:string...>").AddAliases("new")
+	tag := NewCommand("tag", "Tags", nil).
+		AddSubcommand(create).
+		AddSubcommand(NewCommand("delete", "", noop).SetUsage("<name:string>"))
+
+	if tag.GetSubcommand("new") != create || tag.GetSubcommand("create") != create {
+		t.Error("Expected the subcommand to be found by name and alias")
+	}
+	if tag.GetSubcommand("edit") != nil {
+		t.Error("Expected an unknown subcommand to return nil")
+	}
+	if create.FullName() != "tag create" {
+		t.Errorf("Expected the full name to be \"tag create\" but got \"%s\"", create.FullName())
+	}
+	if create.Category != "Tags" {
+		t.Errorf("Expected the subcommand to inherit the category but got \"%s\"", create.Category)
+	}
+
+	tag.Disable()
+	if create.IsEnabled() {
+		t.Error("Expected disabling a command to disable it's subcommands")
+	}
+}
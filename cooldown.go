@@ -0,0 +1,48 @@
+package sapphire
+
+import (
+	"time"
+)
+
+// CooldownScope decides who shares a command's cooldown.
+type CooldownScope int
+
+const (
+	CooldownUser    CooldownScope = iota // Each user has their own cooldown everywhere.
+	CooldownMember                       // Each user has their own cooldown in every guild.
+	CooldownChannel                      // Everyone in a channel shares the cooldown.
+	CooldownGuild                        // Everyone in a guild shares the cooldown.
+	CooldownGlobal                       // Everyone shares the cooldown.
+)
+
+// CooldownBucket limits how many times a command can be used in a window of time.
+// e.g 3 uses per 10 seconds per channel.
+type CooldownBucket struct {
+	Scope  CooldownScope // Who shares the bucket.
+	Uses   int           // How many times the command can be used in a window.
+	Window time.Duration // The window of time, it starts with the first use.
+}
+
+// Returns the key of the bucket the command in ctx falls in.
+func (b *CooldownBucket) key(ctx *CommandContext) string {
+	command := ctx.Command.FullName()
+	switch b.Scope {
+	case CooldownMember:
+		// In DMs there is no guild, the channel is unique enough.
+		if ctx.Guild == nil {
+			return "member:" + ctx.Channel.ID + ":" + ctx.Author.ID + ":" + command
+		}
+		return "member:" + ctx.Guild.ID + ":" + ctx.Author.ID + ":" + command
+	case CooldownChannel:
+		return "channel:" + ctx.Channel.ID + ":" + command
+	case CooldownGuild:
+		if ctx.Guild == nil {
+			return "channel:" + ctx.Channel.ID + ":" + command
+		}
+		return "guild:" + ctx.Guild.ID + ":" + command
+	case CooldownGlobal:
+		return "global:" + command
+	default:
+		return "user:" + ctx.Author.ID + ":" + command
+	}
+}
@@ -0,0 +1,161 @@
+package sapphire
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Human friendly parsing of durations and times used by the duration and time argument types.
+
+// The units understood in durations, months and years are approximated as 30 and 365 days.
+var durationUnits = map[string]time.Duration{
+	"ms":           time.Millisecond,
+	"millisecond":  time.Millisecond,
+	"milliseconds": time.Millisecond,
+	"s":            time.Second,
+	"sec":          time.Second,
+	"secs":         time.Second,
+	"second":       time.Second,
+	"seconds":      time.Second,
+	"m":            time.Minute,
+	"min":          time.Minute,
+	"mins":         time.Minute,
+	"minute":       time.Minute,
+	"minutes":      time.Minute,
+	"h":            time.Hour,
+	"hr":           time.Hour,
+	"hrs":          time.Hour,
+	"hour":         time.Hour,
+	"hours":        time.Hour,
+	"d":            24 * time.Hour,
+	"day":          24 * time.Hour,
+	"days":         24 * time.Hour,
+	"w":            7 * 24 * time.Hour,
+	"wk":           7 * 24 * time.Hour,
+	"wks":          7 * 24 * time.Hour,
+	"week":         7 * 24 * time.Hour,
+	"weeks":        7 * 24 * time.Hour,
+	"mo":           30 * 24 * time.Hour,
+	"month":        30 * 24 * time.Hour,
+	"months":       30 * 24 * time.Hour,
+	"y":            365 * 24 * time.Hour,
+	"yr":           365 * 24 * time.Hour,
+	"yrs":          365 * 24 * time.Hour,
+	"year":         365 * 24 * time.Hour,
+	"years":        365 * 24 * time.Hour,
+}
+
+// Matches a single part of a duration, e.g 30m, 1.5 hours or a week.
+var durationPartRegex = regexp.MustCompile("^(\\d+(?:\\.\\d+)?|an?\\s)\\s*([a-z]+)")
+
+// The Regexp used for matching discord timestamps. e.g <t:1700000000:R>
+var timestampRegex = regexp.MustCompile("^<t:(-?\\d+)(?::[tTdDfFR])?>$")
+
+// The layouts tried for absolute times, they are all in UTC unless they have a zone.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04",
+	"2006/01/02",
+}
+
+// The layouts tried for times of the day, they are the next time that time happens.
+var clockLayouts = []string{"15:04", "15:04:05", "3:04pm", "3pm"}
+
+// Parses durations like 1h30m, 2d, 1 week or 2 hours and 30 minutes.
+func parseDuration(raw string) (time.Duration, bool) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" {
+		return 0, false
+	}
+
+	var total float64
+	for s != "" {
+		match := durationPartRegex.FindStringSubmatch(s)
+		if match == nil {
+			return 0, false
+		}
+		unit, ok := durationUnits[match[2]]
+		if !ok {
+			return 0, false
+		}
+		n := 1.0
+		if !strings.HasPrefix(match[1], "a") {
+			n, _ = strconv.ParseFloat(match[1], 64)
+		}
+		total += n * float64(unit)
+		s = strings.TrimLeft(s[len(match[0]):], " ,")
+		if strings.HasPrefix(s, "and ") {
+			s = strings.TrimLeft(s[len("and "):], " ")
+		}
+	}
+
+	if total > math.MaxInt64 {
+		return 0, false
+	}
+	return time.Duration(total), true
+}
+
+// Parses absolute and relative times relative to now.
+// e.g 2024-01-31 18:00, 18:00, 6pm, tomorrow, in 2 hours, 3 days ago or a discord timestamp.
+func parseTime(raw string, now time.Time) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	s := strings.ToLower(raw)
+
+	switch s {
+	case "now":
+		return now, true
+	case "tomorrow":
+		return now.Add(24 * time.Hour), true
+	case "yesterday":
+		return now.Add(-24 * time.Hour), true
+	}
+
+	if match := timestampRegex.FindStringSubmatch(raw); match != nil {
+		sec, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(sec, 0), true
+	}
+
+	if strings.HasPrefix(s, "in ") {
+		if d, ok := parseDuration(s[len("in "):]); ok {
+			return now.Add(d), true
+		}
+		return time.Time{}, false
+	}
+	if strings.HasSuffix(s, " ago") {
+		if d, ok := parseDuration(strings.TrimSuffix(s, " ago")); ok {
+			return now.Add(-d), true
+		}
+		return time.Time{}, false
+	}
+	if d, ok := parseDuration(s); ok {
+		return now.Add(d), true
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, raw, time.UTC); err == nil {
+			return t, true
+		}
+	}
+
+	now = now.UTC()
+	for _, layout := range clockLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			t = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+			if t.Before(now) {
+				t = t.Add(24 * time.Hour)
+			}
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
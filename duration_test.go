@@ -0,0 +1,53 @@
+package sapphire
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"1h30m":                  90 * time.Minute,
+		"2d":                     48 * time.Hour,
+		"1 week":                 7 * 24 * time.Hour,
+		"2 hours and 30 minutes": 150 * time.Minute,
+		"1.5h":                   90 * time.Minute,
+		"an hour, 5 secs":        time.Hour + 5*time.Second,
+		"3mo":                    90 * 24 * time.Hour,
+	}
+	for input, expected := range cases {
+		if d, ok := parseDuration(input); !ok || d != expected {
+			t.Errorf("parseDuration(%q) = %v, expected %v", input, d, expected)
+		}
+	}
+
+	for _, input := range []string{"", "30", "1 fortnight", "h", "1h and", "week 1"} {
+		if _, ok := parseDuration(input); ok {
+			t.Errorf("Expected parseDuration(%q) to fail", input)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"in 2 hours":       now.Add(2 * time.Hour),
+		"3 days ago":       now.Add(-72 * time.Hour),
+		"1 week":           now.Add(7 * 24 * time.Hour),
+		"tomorrow":         now.Add(24 * time.Hour),
+		"2024-02-01 18:30": time.Date(2024, 2, 1, 18, 30, 0, 0, time.UTC),
+		"2024-02-01":       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"18:00":            time.Date(2024, 1, 31, 18, 0, 0, 0, time.UTC),
+		"9am":              time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+		"<t:1706702400:R>": time.Unix(1706702400, 0),
+	}
+	for input, expected := range cases {
+		if got, ok := parseTime(input, now); !ok || !got.Equal(expected) {
+			t.Errorf("parseTime(%q) = %v, expected %v", input, got, expected)
+		}
+	}
+
+	if _, ok := parseTime("someday", now); ok {
+		t.Error("Expected parseTime(\"someday\") to fail")
+	}
+}
@@ -0,0 +1,194 @@
+package sapphire
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Flag is a flag declared by a command with AddFlag, e.g --days=7
+// Commands that don't declare any flags accept any flag as text through ctx.Flags like before.
+type Flag struct {
+	Name        string   // Name of the flag, e.g days for --days
+	Type        string   // Type of the value, any argument type with constraints or choices, e.g int{1,7}
+	Default     string   // The raw value used when the flag isn't given.
+	Description string   // Shown in help.
+	Aliases     []string // Other names for the flag, single letters are used with one dash e.g -d
+	tag         *UsageTag
+}
+
+// What happens to flags a command didn't declare.
+type UnknownFlagMode int
+
+const (
+	UnknownFlagsError UnknownFlagMode = iota // The command doesn't run and the user is told about the unknown flag.
+	UnknownFlagsKeep                         // The flag is left in the arguments as is.
+)
+
+// Matches a flag token, --name=value, —name or -n=value
+var flagTokenRegex = regexp.MustCompile("^(?:--|—|-)(\\pL[\\w-]*)(?:=(.*))?$")
+
+// AddFlag declares a flag, it's value is parsed as typ and it's available with ctx.FlagArg(name)
+// Use the bool type for flags that are only switched on like --force, --force=no also works for them.
+// Flags of other types take their value after an = or as the next argument, e.g --days=7 or --days 7
+// def is used when the flag isn't given, leave it empty for no default.
+func (c *Command) AddFlag(name, typ, def, description string) *Command {
+	tags, err := ParseUsage("[" + name + ":" + typ + "]")
+	if err != nil {
+		panic(err)
+	}
+	if len(tags) != 1 || tags[0].Rest {
+		panic(fmt.Sprintf("The flag '%s' has an invalid type '%s'.", name, typ))
+	}
+	tag := tags[0]
+	tag.Default = def
+	c.Flags = append(c.Flags, &Flag{Name: name, Type: typ, Default: def, Description: description, Aliases: []string{}, tag: tag})
+	return c
+}
+
+// AddFlagAlias adds another name to the flag called name, e.g AddFlagAlias("force", "f") allows -f
+func (c *Command) AddFlagAlias(name, alias string) *Command {
+	flag := c.GetFlag(name)
+	if flag == nil {
+		panic(fmt.Sprintf("The command '%s' has no flag called '%s'.", c.Name, name))
+	}
+	flag.Aliases = append(flag.Aliases, alias)
+	return c
+}
+
+// GetFlag returns the declared flag called name or with the alias name, returns nil if not found.
+func (c *Command) GetFlag(name string) *Flag {
+	for _, flag := range c.Flags {
+		if flag.Name == name {
+			return flag
+		}
+		for _, alias := range flag.Aliases {
+			if alias == name {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+// Returns how the flag is written in help, e.g --days, -d <int>
+func (flag *Flag) usage() string {
+	names := []string{"--" + flag.Name}
+	for _, alias := range flag.Aliases {
+		if len([]rune(alias)) == 1 {
+			names = append(names, "-"+alias)
+		} else {
+			names = append(names, "--"+alias)
+		}
+	}
+	usage := strings.Join(names, ", ")
+	if flag.tag.Type != "bool" {
+		usage += " <" + flag.Type + ">"
+	}
+	return usage
+}
+
+// Takes the declared flags out of the tokens of content and returns the content without them,
+// the raw values of the flags by name and the names of the unknown flags that were taken out too.
+// A bool flag without a value has it's name as the value like the flags of commands without declared flags.
+func (c *Command) splitFlags(content string, tokens []token, keepUnknown bool) (string, map[string]string, []string) {
+	flags := make(map[string]string)
+	var unknown []string
+	var cut []token
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		// A quoted flag is text.
+		if tok.quoted {
+			continue
+		}
+		// -- ends the flags, everything after it is an argument.
+		if tok.value == "--" {
+			cut = append(cut, tok)
+			break
+		}
+		match := flagTokenRegex.FindStringSubmatch(tok.value)
+		if match == nil {
+			continue
+		}
+		flag := c.GetFlag(match[1])
+		if flag == nil {
+			// Only --name is surely meant as a flag, -word and —word are common in text.
+			if !keepUnknown && strings.HasPrefix(tok.value, "--") {
+				unknown = append(unknown, match[1])
+				cut = append(cut, tok)
+			}
+			continue
+		}
+		cut = append(cut, tok)
+
+		value := match[2]
+		if !strings.Contains(tok.value, "=") {
+			if flag.tag.Type == "bool" {
+				value = flag.Name
+			} else if i+1 < len(tokens) && (tokens[i+1].quoted || !flagTokenRegex.MatchString(tokens[i+1].value)) {
+				// The value is the next argument.
+				i++
+				value = tokens[i].value
+				cut = append(cut, tokens[i])
+			}
+		}
+		flags[flag.Name] = value
+	}
+
+	return cutTokens(content, cut), flags, unknown
+}
+
+// Returns content with the tokens cut out of it along with the whitespace before them.
+func cutTokens(content string, cut []token) string {
+	var b strings.Builder
+	last := 0
+	for _, tok := range cut {
+		b.WriteString(strings.TrimRightFunc(content[last:tok.start], unicode.IsSpace))
+		last = tok.end
+	}
+	b.WriteString(content[last:])
+	return strings.TrimSpace(b.String())
+}
+
+// Parses the values of the declared flags and fills in ctx.FlagArg, returns true on success
+// and on failure it replies with the error and returns false, also fails if unknown flags were given.
+// Like ParseArgs this is called in the command handler and shouldn't be used in normal code.
+func (ctx *CommandContext) ParseFlags() bool {
+	if len(ctx.unknownFlags) > 0 {
+		ctx.ReplyLocale("FLAG_UNKNOWN", "--"+strings.Join(ctx.unknownFlags, ", --"))
+		return false
+	}
+
+	ctx.flagArgs = make(map[string]*Argument)
+	for _, flag := range ctx.Command.Flags {
+		raw, given := ctx.Flags[flag.Name]
+		if flag.tag.Type == "bool" && (raw == flag.Name || (!given && flag.Default == "")) {
+			// Switched on by being given, otherwise it's off.
+			ctx.flagArgs[flag.Name] = &Argument{value: given, provided: given}
+			continue
+		}
+		if given && raw == "" {
+			ctx.ReplyLocale("FLAG_VALUE_REQUIRED", flag.Name)
+			return false
+		}
+		a, err := ParseArgument(ctx, flag.tag, raw)
+		if err != nil {
+			ctx.Reply("%s", err.Error())
+			return false
+		}
+		ctx.flagArgs[flag.Name] = a
+	}
+	return true
+}
+
+// FlagArg returns the parsed value of a declared flag, it returns an empty arg if the flag wasn't given and has no default.
+// Bool flags without a default are false when they aren't given.
+// e.g with AddFlag("days", "int", "0", "...") ctx.FlagArg("days").AsInt()
+func (ctx *CommandContext) FlagArg(name string) *Argument {
+	if a, ok := ctx.flagArgs[name]; ok {
+		return a
+	}
+	return &Argument{provided: false}
+}
@@ -0,0 +1,64 @@
+package sapphire
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFlags(t *testing.T) {
+	cmd := NewCommand("ban", "Moderation", nil).
+		AddFlag("days", "int{0,7}", "0", "Days of messages to delete.").
+		AddFlag("force", "bool", "", "Skip the confirmation.").
+		AddFlagAlias("days", "d").
+		AddFlagAlias("force", "f")
+
+	cases := []struct {
+		content string
+		rest    string
+		flags   map[string]string
+		unknown []string
+	}{
+		{"someone --days=3 being rude", "someone being rude", map[string]string{"days": "3"}, nil},
+		{"someone -d 3 -f being rude", "someone being rude", map[string]string{"days": "3", "force": "force"}, nil},
+		{"someone —force --typo rude", "someone rude", map[string]string{"force": "force"}, []string{"typo"}},
+		{`someone "--days" -- --force`, `someone "--days" --force`, map[string]string{}, nil},
+		{"someone -5 points", "someone -5 points", map[string]string{}, nil},
+		{"spamming —again -x", "spamming —again -x", map[string]string{}, nil},
+	}
+
+	for _, c := range cases {
+		rest, flags, unknown := cmd.splitFlags(c.content, tokenize(c.content), false)
+		if rest != c.rest || !reflect.DeepEqual(flags, c.flags) || !reflect.DeepEqual(unknown, c.unknown) {
+			t.Errorf("splitFlags(%q) = %q, %v, %v expected %q, %v, %v", c.content, rest, flags, unknown, c.rest, c.flags, c.unknown)
+		}
+	}
+
+	rest, _, unknown := cmd.splitFlags("someone --typo", tokenize("someone --typo"), true)
+	if rest != "someone --typo" || len(unknown) != 0 {
+		t.Errorf("Expected unknown flags to be kept but got %q, %v", rest, unknown)
+	}
+}
+
+func TestFlagArgumentClash(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a flag named like an argument to panic")
+		}
+	}()
+	newTestBot(t).AddCommand(NewCommand("ban", "Moderation", nil).SetUsage("<days:int>").AddFlag("days", "int", "", ""))
+}
+
+func TestFlagUsage(t *testing.T) {
+	cmd := NewCommand("ban", "Moderation", nil).
+		AddFlag("days", "int{0,7}", "0", "").
+		AddFlag("force", "bool", "", "").
+		AddFlagAlias("days", "d").
+		AddFlagAlias("force", "yes")
+
+	if usage := cmd.GetFlag("d").usage(); usage != "--days, -d <int{0,7}>" {
+		t.Errorf("Unexpected usage %q", usage)
+	}
+	if usage := cmd.GetFlag("force").usage(); usage != "--force, --yes" {
+		t.Errorf("Unexpected usage %q", usage)
+	}
+}
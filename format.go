@@ -0,0 +1,538 @@
+package sapphire
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Params names the arguments of a localized string, pass it as the last argument of ReplyLocale, Localize or Get.
+// Strings with named placeholders use an ICU-like message format instead of Sprintf:
+// {name} inserts a value, numbers and times are formatted for the language's locale.
+// {name, number}, {name, number, integer}, {name, number, percent} or {name, number, ::.00} formats a number.
+// {name, date} and {name, time} format a time.Time as a date or a time of day.
+// {count, plural, =0 {no items} one {# item} other {# items}} picks a branch by the plural category of count, # is the number.
+// {gender, select, male {He} female {She} other {They}} picks a branch by the value.
+// Positional arguments can be used as {0}, {1} and so on so translators can use the format for any key,
+// but without Params a string with Printf verbs is formatted with Sprintf and it's braces are left as is.
+// Quote braces with apostrophes to write them as is, e.g '{' and write two apostrophes for an apostrophe next to them.
+type Params map[string]interface{}
+
+// Detects strings using the message format, Sprintf strings don't have placeholders like {name} or {0, plural, ...}
+var messageFormatRegex = regexp.MustCompile("\\{\\s*[\\pL\\pN_]+\\s*[,}]")
+
+func isMessageFormat(message string) bool {
+	return messageFormatRegex.MatchString(message)
+}
+
+// Detects Sprintf verbs left in a message format string, they would be printed as is. e.g "{prefix}help %s"
+// Verbs with a space flag are left out since "50% off" is common in text.
+var sprintfVerbRegex = regexp.MustCompile("%(?:\\[\\d+\\])?[-+#0]*\\d*(?:\\.\\d*)?[vTtbcdoOqxXUeEfFgGsp]")
+
+func hasSprintfVerbs(message string) bool {
+	return sprintfVerbRegex.MatchString(strings.ReplaceAll(message, "%%", ""))
+}
+
+// Rules for a locale used by the message format.
+type LocaleRules struct {
+	Plural     func(n float64) string // Returns the plural category of n: zero, one, two, few, many or other.
+	Decimal    string                 // The decimal separator.
+	Group      string                 // The separator between groups of thousands.
+	DateLayout string                 // The time layout for dates.
+	TimeLayout string                 // The time layout for times of day.
+}
+
+// Returns whether n is a whole number, plural rules for whole numbers don't apply to fractions.
+func isInteger(n float64) bool {
+	return n == math.Trunc(n)
+}
+
+func pluralOneOther(n float64) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+// French and Portuguese treat 0 and 1 the same.
+func pluralZeroOne(n float64) string {
+	if n >= 0 && n < 2 {
+		return "one"
+	}
+	return "other"
+}
+
+func pluralNone(n float64) string {
+	return "other"
+}
+
+// Russian and Ukrainian.
+func pluralSlavic(n float64) string {
+	if !isInteger(n) {
+		return "other"
+	}
+	mod10, mod100 := math.Mod(n, 10), math.Mod(n, 100)
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return "one"
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return "few"
+	}
+	return "many"
+}
+
+func pluralPolish(n float64) string {
+	if !isInteger(n) {
+		return "other"
+	}
+	mod10, mod100 := math.Mod(n, 10), math.Mod(n, 100)
+	switch {
+	case n == 1:
+		return "one"
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return "few"
+	}
+	return "many"
+}
+
+// Czech and Slovak.
+func pluralCzech(n float64) string {
+	switch {
+	case !isInteger(n):
+		return "many"
+	case n == 1:
+		return "one"
+	case n >= 2 && n <= 4:
+		return "few"
+	}
+	return "other"
+}
+
+func pluralArabic(n float64) string {
+	if !isInteger(n) {
+		return "other"
+	}
+	mod100 := math.Mod(n, 100)
+	switch {
+	case n == 0:
+		return "zero"
+	case n == 1:
+		return "one"
+	case n == 2:
+		return "two"
+	case mod100 >= 3 && mod100 <= 10:
+		return "few"
+	case mod100 >= 11:
+		return "many"
+	}
+	return "other"
+}
+
+// The rules of the builtin locales, languages are matched by their name and then by the language without the region.
+// e.g pt-BR uses pt and en-GB uses en, unknown languages use en-US.
+var localeRules = map[string]*LocaleRules{
+	"en-US": {pluralOneOther, ".", ",", "01/02/2006", "3:04 PM"},
+	"en":    {pluralOneOther, ".", ",", "02/01/2006", "15:04"},
+	"de":    {pluralOneOther, ",", ".", "02.01.2006", "15:04"},
+	"nl":    {pluralOneOther, ",", ".", "02-01-2006", "15:04"},
+	"it":    {pluralOneOther, ",", ".", "02/01/2006", "15:04"},
+	"es":    {pluralOneOther, ",", ".", "02/01/2006", "15:04"},
+	"tr":    {pluralOneOther, ",", ".", "02.01.2006", "15:04"},
+	"sv":    {pluralOneOther, ",", " ", "2006-01-02", "15:04"},
+	"da":    {pluralOneOther, ",", ".", "02.01.2006", "15.04"},
+	"nb":    {pluralOneOther, ",", " ", "02.01.2006", "15:04"},
+	"fi":    {pluralOneOther, ",", " ", "2.1.2006", "15.04"},
+	"fr":    {pluralZeroOne, ",", " ", "02/01/2006", "15:04"},
+	"pt":    {pluralZeroOne, ",", ".", "02/01/2006", "15:04"},
+	"ru":    {pluralSlavic, ",", " ", "02.01.2006", "15:04"},
+	"uk":    {pluralSlavic, ",", " ", "02.01.2006", "15:04"},
+	"pl":    {pluralPolish, ",", " ", "02.01.2006", "15:04"},
+	"cs":    {pluralCzech, ",", " ", "2. 1. 2006", "15:04"},
+	"ar":    {pluralArabic, ".", ",", "02/01/2006", "15:04"},
+	"ja":    {pluralNone, ".", ",", "2006/01/02", "15:04"},
+	"zh":    {pluralNone, ".", ",", "2006/1/2", "15:04"},
+	"ko":    {pluralNone, ".", ",", "2006. 1. 2.", "15:04"},
+}
+
+// Returns the rules for the locale called name.
+func rulesFor(name string) *LocaleRules {
+	if rules, ok := localeRules[name]; ok {
+		return rules
+	}
+	if i := strings.IndexAny(name, "-_"); i > 0 {
+		if rules, ok := localeRules[strings.ToLower(name[:i])]; ok {
+			return rules
+		}
+	}
+	return localeRules["en-US"]
+}
+
+// Formats messages in the message format for a language.
+type messageFormatter struct {
+	rules  *LocaleRules
+	args   []interface{}
+	params Params
+}
+
+// Formats message, syntax errors return the error with the message formatted as far as it could.
+func formatMessage(rules *LocaleRules, message string, args []interface{}, params Params) (string, error) {
+	f := &messageFormatter{rules: rules, args: args, params: params}
+	return f.message(message, "")
+}
+
+// Formats a message, hash is what # is replaced with inside plural branches.
+func (f *messageFormatter) message(message, hash string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		switch c := message[i]; {
+		case c == '\'' && i+1 < len(message) && message[i+1] == '\'':
+			// '' is an apostrophe.
+			b.WriteByte('\'')
+			i++
+		case quoteEnd(message, i) >= 0:
+			end := quoteEnd(message, i)
+			b.WriteString(message[i+1 : end])
+			i = end
+		case c == '#' && hash != "":
+			b.WriteString(hash)
+		case c == '{':
+			end, err := closingBrace(message, i)
+			if err != nil {
+				b.WriteString(message[i:])
+				return b.String(), err
+			}
+			value, err := f.argument(message[i+1:end], hash)
+			if err != nil {
+				return b.String(), err
+			}
+			b.WriteString(value)
+			i = end
+		case c == '}':
+			return b.String(), errors.New("unexpected }")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// Returns the index of the apostrophe closing the quote started at i, or -1 if no quote starts at i.
+// An apostrophe only starts a quote before a special character so words like don't are left alone, unclosed quotes run to the end.
+func quoteEnd(message string, i int) int {
+	if message[i] != '\'' || i+1 >= len(message) || strings.IndexByte("{}#", message[i+1]) < 0 {
+		return -1
+	}
+	end := strings.IndexByte(message[i+1:], '\'')
+	if end < 0 {
+		return len(message)
+	}
+	return i + 1 + end
+}
+
+// Returns the index of the brace closing the one at start, braces in quotes are skipped.
+func closingBrace(message string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(message); i++ {
+		switch message[i] {
+		case '\'':
+			if end := quoteEnd(message, i); end >= 0 {
+				i = end
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return -1, errors.New("unclosed {")
+}
+
+// Returns the first syntax error in message, unlike formatting every branch of plurals and selects is checked.
+func checkMessage(message string) error {
+	for i := 0; i < len(message); i++ {
+		switch {
+		case quoteEnd(message, i) >= 0:
+			i = quoteEnd(message, i)
+		case message[i] == '}':
+			return errors.New("unexpected }")
+		case message[i] == '{':
+			end, err := closingBrace(message, i)
+			if err != nil {
+				return err
+			}
+			parts := strings.SplitN(message[i+1:end], ",", 3)
+			typ, style := "", ""
+			if len(parts) > 1 {
+				typ = strings.TrimSpace(parts[1])
+			}
+			if len(parts) > 2 {
+				style = strings.TrimSpace(parts[2])
+			}
+			switch typ {
+			case "", "date", "time":
+			case "number":
+				f := &messageFormatter{rules: localeRules["en-US"]}
+				if _, err := f.number(0, style); err != nil {
+					return err
+				}
+			case "plural", "select":
+				branches, err := parseBranches(style)
+				if err != nil {
+					return err
+				}
+				for _, branch := range branches {
+					if err := checkMessage(branch); err != nil {
+						return err
+					}
+				}
+			default:
+				return fmt.Errorf("unknown placeholder type %s", typ)
+			}
+			i = end
+		}
+	}
+	return nil
+}
+
+// Returns the names of the placeholders in message sorted, including the ones in plural and select branches.
+// Syntax errors are skipped, see checkMessage.
+func placeholderNames(message string) []string {
+	seen := make(map[string]bool)
+	var walk func(message string)
+	walk = func(message string) {
+		for i := 0; i < len(message); i++ {
+			switch {
+			case quoteEnd(message, i) >= 0:
+				i = quoteEnd(message, i)
+			case message[i] == '{':
+				end, err := closingBrace(message, i)
+				if err != nil {
+					return
+				}
+				parts := strings.SplitN(message[i+1:end], ",", 3)
+				seen[strings.TrimSpace(parts[0])] = true
+				if len(parts) > 2 {
+					if typ := strings.TrimSpace(parts[1]); typ == "plural" || typ == "select" {
+						branches, _ := parseBranches(strings.TrimSpace(parts[2]))
+						for _, branch := range branches {
+							walk(branch)
+						}
+					}
+				}
+				i = end
+			}
+		}
+	}
+	walk(message)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Formats the inside of a placeholder, e.g name, plural, one {# item} other {# items}
+// hash is passed down to select branches inside plural branches.
+func (f *messageFormatter) argument(arg, hash string) (string, error) {
+	parts := strings.SplitN(arg, ",", 3)
+	name := strings.TrimSpace(parts[0])
+	typ := ""
+	if len(parts) > 1 {
+		typ = strings.TrimSpace(parts[1])
+	}
+	style := ""
+	if len(parts) > 2 {
+		style = strings.TrimSpace(parts[2])
+	}
+
+	value, ok := f.value(name)
+	if !ok {
+		// Leave unknown placeholders as is so the mistake is visible.
+		return "{" + arg + "}", nil
+	}
+
+	switch typ {
+	case "":
+		return f.format(value), nil
+	case "number":
+		n, ok := toFloat(value)
+		if !ok {
+			return fmt.Sprint(value), nil
+		}
+		return f.number(n, style)
+	case "date", "time":
+		t, ok := value.(time.Time)
+		if !ok {
+			return fmt.Sprint(value), nil
+		}
+		if typ == "date" {
+			return t.Format(f.rules.DateLayout), nil
+		}
+		return t.Format(f.rules.TimeLayout), nil
+	case "plural", "select":
+		branches, err := parseBranches(style)
+		if err != nil {
+			return "", err
+		}
+		if typ == "select" {
+			branch, ok := branches[fmt.Sprint(value)]
+			if !ok {
+				branch = branches["other"]
+			}
+			return f.message(branch, hash)
+		}
+		n, _ := toFloat(value)
+		branch, ok := branches["="+strconv.FormatFloat(n, 'f', -1, 64)]
+		if !ok {
+			branch, ok = branches[f.rules.Plural(n)]
+		}
+		if !ok {
+			branch = branches["other"]
+		}
+		hash, _ := f.number(n, "")
+		return f.message(branch, hash)
+	}
+	return "", fmt.Errorf("unknown placeholder type %s", typ)
+}
+
+// Returns the value of a named or positional argument.
+func (f *messageFormatter) value(name string) (interface{}, bool) {
+	if value, ok := f.params[name]; ok {
+		return value, true
+	}
+	if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < len(f.args) {
+		return f.args[i], true
+	}
+	return nil, false
+}
+
+// Parses the branches of a plural or select, e.g one {# item} other {# items}
+// Every plural and select needs an other branch.
+func parseBranches(style string) (map[string]string, error) {
+	branches := make(map[string]string)
+	for i := 0; i < len(style); {
+		if style[i] == ' ' || style[i] == '\t' || style[i] == '\n' {
+			i++
+			continue
+		}
+		start := strings.IndexByte(style[i:], '{')
+		if start < 0 {
+			return nil, fmt.Errorf("the branch %s has no message", strings.TrimSpace(style[i:]))
+		}
+		selector := strings.TrimSpace(style[i : i+start])
+		end, err := closingBrace(style, i+start)
+		if err != nil {
+			return nil, err
+		}
+		branches[selector] = style[i+start+1 : end]
+		i = end + 1
+	}
+	if _, ok := branches["other"]; !ok {
+		return nil, errors.New("plural and select need an other branch")
+	}
+	return branches, nil
+}
+
+// Formats a value without a type, numbers and times are formatted for the locale.
+func (f *messageFormatter) format(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(f.rules.DateLayout + " " + f.rules.TimeLayout)
+	}
+	if n, ok := toFloat(value); ok {
+		s, _ := f.number(n, "")
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// Formats n for the locale, style is integer, percent or a skeleton like ::.00 (exactly 2 decimals) or ::.## (at most 2)
+// The default is at most 3 decimals.
+func (f *messageFormatter) number(n float64, style string) (string, error) {
+	min, max := 0, 3
+	suffix := ""
+	switch {
+	case style == "":
+	case style == "integer":
+		max = 0
+	case style == "percent":
+		n *= 100
+		max = 0
+		suffix = "%"
+	case strings.HasPrefix(style, "::."):
+		digits := style[3:]
+		if strings.Trim(digits, "0#") != "" {
+			return "", fmt.Errorf("invalid number style %s", style)
+		}
+		min = strings.Count(digits, "0")
+		max = len(digits)
+	default:
+		return "", fmt.Errorf("invalid number style %s", style)
+	}
+
+	s := strconv.FormatFloat(math.Abs(n), 'f', max, 64)
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	for len(fraction) > min && strings.HasSuffix(fraction, "0") {
+		fraction = fraction[:len(fraction)-1]
+	}
+
+	var b strings.Builder
+	if n < 0 && strings.Trim(whole+fraction, "0") != "" {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.rules.Group)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(f.rules.Decimal)
+		b.WriteString(fraction)
+	}
+	b.WriteString(suffix)
+	return b.String(), nil
+}
+
+// Converts any number to a float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
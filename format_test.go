@@ -0,0 +1,114 @@
+package sapphire
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMessageFormat(t *testing.T) {
+	items := "{count, plural, =0 {no items} one {# item} other {# items}}"
+	date := time.Date(2024, 1, 31, 18, 5, 0, 0, time.UTC)
+
+	cases := []struct {
+		locale  string
+		message string
+		args    []interface{}
+		output  string
+	}{
+		{"en-US", items, []interface{}{Params{"count": 0}}, "no items"},
+		{"en-US", items, []interface{}{Params{"count": 1}}, "1 item"},
+		{"en-US", items, []interface{}{Params{"count": 1234.5}}, "1,234.5 items"},
+		{"de-DE", items, []interface{}{Params{"count": 1234.5}}, "1.234,5 items"},
+		{"fr-FR", "{n, plural, one {# jour} other {# jours}}", []interface{}{Params{"n": 0}}, "0 jour"},
+		{"ru", "{n, plural, one {# день} few {# дня} many {# дней} other {# дня}}", []interface{}{Params{"n": 22}}, "22 дня"},
+		{"ru", "{n, plural, one {# день} few {# дня} many {# дней} other {# дня}}", []interface{}{Params{"n": 11}}, "11 дней"},
+		{"ar", "{n, plural, zero {صفر} one {واحد} two {اثنان} few {قليل} many {كثير} other {آخر}}", []interface{}{Params{"n": 2}}, "اثنان"},
+		{"en-US", "{gender, select, male {He} female {She} other {They}} replied.", []interface{}{Params{"gender": "female"}}, "She replied."},
+		{"en-US", "{gender, select, male {He} female {She} other {They}} replied.", []interface{}{Params{}}, "{gender, select, male {He} female {She} other {They}} replied."},
+		{"en-US", "{name} has {n, plural, one {# {kind}} other {# {kind}s}}", []interface{}{Params{"name": "Bob", "n": 2, "kind": "role"}}, "Bob has 2 roles"},
+		{"en-US", "{0} and {1}", []interface{}{"a", 2}, "a and 2"},
+		{"en-US", "{n, number, ::.000} {n, number, integer} {p, number, percent}", []interface{}{Params{"n": 2.75, "p": 0.25}}, "2.750 3 25%"},
+		{"en-US", "{when, date} {when, time}", []interface{}{Params{"when": date}}, "01/31/2024 6:05 PM"},
+		{"de", "{when}", []interface{}{Params{"when": date}}, "31.01.2024 18:05"},
+		{"en-US", "Use '{name}' like this, don't forget '#' or it''s {name}", []interface{}{Params{"name": "x"}}, "Use {name} like this, don't forget # or it's x"},
+		{"en-US", "Sprintf %s still works", []interface{}{"fine", Params{"ignored": true}}, "Sprintf fine still works"},
+		{"en-US", "{missing} stays", []interface{}{Params{}}, "{missing} stays"},
+	}
+
+	for _, c := range cases {
+		if output := NewLanguage(c.locale).Format(c.message, c.args...); output != c.output {
+			t.Errorf("Formatting %q in %s gave %q, expected %q", c.message, c.locale, output, c.output)
+		}
+	}
+}
+
+func TestCheckMessage(t *testing.T) {
+	valid := []string{
+		"{n, plural, one {# item} other {# items}}",
+		"'{' quoted {name}",
+		"{a, select, x {{n, number, ::.0#}} other {}}",
+	}
+	invalid := []string{
+		"{n, plural, one {# item}}",
+		"{n, plural, one {# item} other {# items}",
+		"{a, select, x {{n, number, ::.0a}} other {}}",
+		"{n, currency}",
+		"{name}}",
+	}
+
+	for _, message := range valid {
+		if err := checkMessage(message); err != nil {
+			t.Errorf("Expected %q to be valid but got %v", message, err)
+		}
+	}
+	for _, message := range invalid {
+		if err := checkMessage(message); err == nil {
+			t.Errorf("Expected %q to be invalid", message)
+		}
+	}
+}
+
+func TestMixedFormats(t *testing.T) {
+	cases := []struct {
+		value     string
+		reference string
+		mixed     bool
+	}{
+		{"Use {prefix}help for %s", "Use {prefix}help for {command}", true},
+		{"{n} of %[1]d", "{n} of {total}", true},
+		{"{n, number, percent} 50% off", "{n} off", false},
+		{"{n}%% done", "{n} done", false},
+		{"Use {prefix}help for %s", "Use %s for help", false},
+		{"Use %s for help", "Use %s for help", false},
+	}
+	for _, c := range cases {
+		if err := checkKey("HELP", c.value, c.reference); (err != nil) != c.mixed {
+			t.Errorf("Expected %q to be mixed: %t but got %v", c.value, c.mixed, err)
+		}
+	}
+}
+
+func TestBracesWithVerbs(t *testing.T) {
+	lang := NewLanguage("en-US").Set("HELP", "Use {prefix}help for %s, it's '{' is literal")
+	if s := lang.Get("HELP", "ping"); s != "Use {prefix}help for ping, it's '{' is literal" {
+		t.Errorf("Expected a string with verbs and no Params to use Sprintf but got %q", s)
+	}
+	if s := lang.Get("HELP", Params{"prefix": "!"}); s != "Use !help for %s, it's { is literal" {
+		t.Errorf("Expected Params to use named placeholders but got %q", s)
+	}
+	if s := NewLanguage("en-US").Format("{1} before {0}", "a", "b"); s != "b before a" {
+		t.Errorf("Expected positional placeholders without verbs to work but got %q", s)
+	}
+}
+
+func TestCooldownPlural(t *testing.T) {
+	if s := English.Get("COMMAND_COOLDOWN", 1, Params{"seconds": 1.0}); s != "You can use this command again in 1 second." {
+		t.Errorf("Unexpected %q", s)
+	}
+	if s := English.Get("COMMAND_COOLDOWN", 3, Params{"seconds": 2.25}); s != "You can use this command again in 2.25 seconds." {
+		t.Errorf("Unexpected %q", s)
+	}
+	if s := NewLanguage("de-DE").Set("COMMAND_COOLDOWN", "Warte noch %d Sekunden.").Get("COMMAND_COOLDOWN", 3, Params{"seconds": 2.25}); s != "Warte noch 3 Sekunden." {
+		t.Errorf("Expected Sprintf translations to keep working but got %q", s)
+	}
+}
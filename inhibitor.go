@@ -0,0 +1,180 @@
+package sapphire
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// InhibitorHandler decides if a command can run, returning false stops the command from running.
+// The reason is replied to the user, return an empty reason to stop silently.
+type InhibitorHandler func(ctx *CommandContext) (ok bool, reason string)
+
+// Inhibitor is a check that runs before every command, see bot.AddInhibitor
+type Inhibitor struct {
+	Name string           // Name of the inhibitor.
+	Run  InhibitorHandler // The actual handler function.
+}
+
+// CommandHook is a function called around every command's execution, see bot.AddPreRunHook and bot.AddPostRunHook
+type CommandHook func(ctx *CommandContext)
+
+// AddInhibitor adds an inhibitor to the end of the chain, inhibitors run in order before the arguments are parsed
+// and the first one to fail stops the command.
+// If an inhibitor with the same name exists it is replaced while keeping it's position.
+func (bot *Bot) AddInhibitor(name string, fn InhibitorHandler) *Bot {
+	for _, inhibitor := range bot.Inhibitors {
+		if inhibitor.Name == name {
+			inhibitor.Run = fn
+			return bot
+		}
+	}
+	bot.Inhibitors = append(bot.Inhibitors, &Inhibitor{Name: name, Run: fn})
+	return bot
+}
+
+// InsertInhibitor adds an inhibitor right before the inhibitor named before.
+// If before doesn't exist it is added to the end of the chain.
+// Together with RemoveInhibitor this can be used to reorder the chain.
+func (bot *Bot) InsertInhibitor(before, name string, fn InhibitorHandler) *Bot {
+	bot.RemoveInhibitor(name)
+	for i, inhibitor := range bot.Inhibitors {
+		if inhibitor.Name == before {
+			bot.Inhibitors = append(bot.Inhibitors[:i], append([]*Inhibitor{{Name: name, Run: fn}}, bot.Inhibitors[i:]...)...)
+			return bot
+		}
+	}
+	return bot.AddInhibitor(name, fn)
+}
+
+// RemoveInhibitor removes the inhibitor with the given name, this includes the builtin ones.
+func (bot *Bot) RemoveInhibitor(name string) *Bot {
+	for i, inhibitor := range bot.Inhibitors {
+		if inhibitor.Name == name {
+			bot.Inhibitors = append(bot.Inhibitors[:i], bot.Inhibitors[i+1:]...)
+			break
+		}
+	}
+	return bot
+}
+
+// AddPreRunHook adds a hook that is called right before a command runs, after all inhibitors passed and the arguments are parsed.
+func (bot *Bot) AddPreRunHook(hook CommandHook) *Bot {
+	bot.preRunHooks = append(bot.preRunHooks, hook)
+	return bot
+}
+
+// AddPostRunHook adds a hook that is called after a command finished running.
+// It is not called if the command panicked, the error handler gets called instead.
+func (bot *Bot) AddPostRunHook(hook CommandHook) *Bot {
+	bot.postRunHooks = append(bot.postRunHooks, hook)
+	return bot
+}
+
+// Runs the inhibitors in order, returns false if the command cannot run after replying with the reason.
+func (bot *Bot) runInhibitors(ctx *CommandContext) bool {
+	for _, inhibitor := range bot.Inhibitors {
+		ok, reason := inhibitor.Run(ctx)
+		if !ok {
+			if reason != "" {
+				ctx.Reply(reason)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// The builtin inhibitors, they are added by default in this order as
+// "disabled", "ownerOnly", "guildOnly", "permissions" and "cooldown"
+// They are exported so they can be added back after removing them.
+
+// DisabledInhibitor stops disabled commands.
+func DisabledInhibitor(ctx *CommandContext) (bool, string) {
+	if !ctx.Command.IsEnabled() {
+		return false, ctx.Localize("COMMAND_DISABLED")
+	}
+	return true, ""
+}
+
+// OwnerOnlyInhibitor stops owner only commands from being used by anyone else.
+func OwnerOnlyInhibitor(ctx *CommandContext) (bool, string) {
+	if ctx.Command.IsOwnerOnly() && ctx.Author.ID != ctx.Bot.OwnerID {
+		return false, ctx.Localize("COMMAND_OWNER_ONLY")
+	}
+	return true, ""
+}
+
+// GuildOnlyInhibitor stops guild only commands from being used in DMs.
+func GuildOnlyInhibitor(ctx *CommandContext) (bool, string) {
+	if ctx.Command.IsGuildOnly() && ctx.Guild == nil {
+		return false, ctx.Localize("COMMAND_GUILD_ONLY")
+	}
+	return true, ""
+}
+
+// PermissionsInhibitor checks the permissions the user and the bot need for the command and it's parents.
+func PermissionsInhibitor(ctx *CommandContext) (bool, string) {
+	// Permissions only exist in guilds.
+	if ctx.Guild == nil {
+		return true, ""
+	}
+
+	if required := ctx.Command.requiredPermissions(); required != 0 {
+		member, err := ctx.FetchMember(ctx.Author.ID)
+		if err != nil {
+			ctx.Bot.ErrorHandler(ctx.Bot, err)
+			return false, ""
+		}
+		if perms := PermissionsForMemberInChannel(ctx.Guild, ctx.Channel, member); !perms.Has(required) {
+			return false, ctx.Localize("COMMAND_MISSING_PERMISSIONS", strings.Join(perms.Missing(required), ", "))
+		}
+	}
+
+	if required := ctx.Command.botPermissions(); required != 0 {
+		member, err := ctx.FetchMember(ctx.Session.State.User.ID)
+		if err != nil {
+			ctx.Bot.ErrorHandler(ctx.Bot, err)
+			return false, ""
+		}
+		if perms := PermissionsForMemberInChannel(ctx.Guild, ctx.Channel, member); !perms.Has(required) {
+			return false, ctx.Localize("COMMAND_BOT_MISSING_PERMISSIONS", strings.Join(perms.Missing(required), ", "))
+		}
+	}
+
+	return true, ""
+}
+
+// CooldownInhibitor stops the command if the command's cooldown bucket is empty.
+// The use is only taken once the arguments are parsed so a typo doesn't cost the user a use.
+func CooldownInhibitor(ctx *CommandContext) (bool, string) {
+	bucket := ctx.Command.cooldownBucket()
+	if bucket == nil {
+		return true, ""
+	}
+	if canRun, after := ctx.Bot.CommandCooldowns.Peek(bucket.key(ctx), bucket.Uses, bucket.Window); !canRun {
+		return false, cooldownReason(ctx, after)
+	}
+	ctx.cooldown = bucket
+	return true, ""
+}
+
+// Takes a use from the bucket CooldownInhibitor checked, another command may have taken the last use meanwhile.
+func (ctx *CommandContext) takeCooldown() bool {
+	bucket := ctx.cooldown
+	if bucket == nil {
+		return true
+	}
+	if canRun, after := ctx.Bot.CommandCooldowns.Check(bucket.key(ctx), bucket.Uses, bucket.Window); !canRun {
+		ctx.Reply(cooldownReason(ctx, after))
+		return false
+	}
+	return true
+}
+
+func cooldownReason(ctx *CommandContext, after time.Duration) string {
+	// Rounded up so it never says 0 seconds.
+	// Sprintf translations get whole seconds like they always did (%d), named placeholders get the precise {seconds}
+	seconds := math.Ceil(after.Seconds()*100) / 100
+	return ctx.Localize("COMMAND_COOLDOWN", int(math.Ceil(seconds)), Params{"seconds": seconds})
+}
@@ -0,0 +1,74 @@
+package sapphire
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"strings"
+	"testing"
+)
+
+func TestInhibitorOrder(t *testing.T) {
+	bot := newTestBot(t)
+	names := func() string {
+		var list []string
+		for _, inhibitor := range bot.Inhibitors {
+			list = append(list, inhibitor.Name)
+		}
+		return strings.Join(list, ",")
+	}
+	pass := func(ctx *CommandContext) (bool, string) { return true, "" }
+
+	if res := names(); res != "disabled,ownerOnly,guildOnly,permissions,cooldown" {
+		t.Errorf("Unexpected default inhibitors: %s", res)
+	}
+
+	bot.AddInhibitor("blacklist", pass).
+		InsertInhibitor("disabled", "maintenance", pass).
+		RemoveInhibitor("guildOnly").
+		InsertInhibitor("ownerOnly", "cooldown", CooldownInhibitor)
+
+	if res := names(); res != "maintenance,disabled,cooldown,ownerOnly,permissions,blacklist" {
+		t.Errorf("Unexpected inhibitors after reordering: %s", res)
+	}
+}
+
+func TestSubcommandInheritsChecks(t *testing.T) {
+	bot := newTestBot(t)
+	bot.OwnerID = "owner"
+	eval := NewCommand("eval", "Owner", func(ctx *CommandContext) {})
+	bot.AddCommand(NewCommand("admin", "Owner", nil).SetOwnerOnly(true).SetGuildOnly(true).AddSubcommand(eval))
+
+	ctx := &CommandContext{Bot: bot, Command: eval, Author: &discordgo.User{ID: "someone"}, Locale: English}
+	if ok, _ := OwnerOnlyInhibitor(ctx); ok {
+		t.Error("Expected the subcommand of an owner only command to be owner only")
+	}
+	if ok, _ := GuildOnlyInhibitor(ctx); ok {
+		t.Error("Expected the subcommand of a guild only command to be guild only")
+	}
+	ctx.Author.ID = "owner"
+	if ok, _ := OwnerOnlyInhibitor(ctx); !ok {
+		t.Error("Expected the owner to pass")
+	}
+}
+
+func TestSubcommandInheritsPermissions(t *testing.T) {
+	bot := newTestBot(t)
+	guild := &discordgo.Guild{
+		ID:      "100",
+		OwnerID: "1",
+		Roles:   []*discordgo.Role{{ID: "100", Permissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages}},
+		Members: []*discordgo.Member{{GuildID: "100", User: &discordgo.User{ID: "2"}}},
+	}
+	bot.Session.State.GuildAdd(guild)
+	warn := NewCommand("warn", "Moderation", func(ctx *CommandContext) {}).SetBotPermissions(discordgo.PermissionSendMessages)
+	bot.AddCommand(NewCommand("mod", "Moderation", nil).SetRequiredPermissions(discordgo.PermissionKickMembers).
+		SetBotPermissions(discordgo.PermissionManageRoles).AddSubcommand(warn))
+
+	if perms := warn.botPermissions(); perms != discordgo.PermissionSendMessages|discordgo.PermissionManageRoles {
+		t.Errorf("Expected the bot permissions of the parent to be included but got %d", perms)
+	}
+	ctx := &CommandContext{Bot: bot, Command: warn, Session: bot.Session, Guild: guild, Channel: &discordgo.Channel{ID: "500"},
+		Author: &discordgo.User{ID: "2"}, Locale: English}
+	if ok, reason := PermissionsInhibitor(ctx); ok || !strings.Contains(reason, "Kick Members") {
+		t.Errorf("Expected the subcommand of a gated command to need it's permissions but got %v %q", ok, reason)
+	}
+}
@@ -0,0 +1,369 @@
+package sapphire
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Application commands (slash commands) share the same command definitions as message commands.
+// The usage tags are converted to typed options and when invoked the options are converted back to raw arguments
+// so they go through the same inhibitors, argument parsing and handler.
+
+// The application command option types for argument types, everything else is sent as a string and parsed as usual.
+var applicationOptionTypes = map[string]discordgo.ApplicationCommandOptionType{
+	"num":     discordgo.ApplicationCommandOptionInteger,
+	"number":  discordgo.ApplicationCommandOptionInteger,
+	"int":     discordgo.ApplicationCommandOptionInteger,
+	"float":   discordgo.ApplicationCommandOptionNumber,
+	"bool":    discordgo.ApplicationCommandOptionBoolean,
+	"user":    discordgo.ApplicationCommandOptionUser,
+	"member":  discordgo.ApplicationCommandOptionUser,
+	"chan":    discordgo.ApplicationCommandOptionChannel,
+	"channel": discordgo.ApplicationCommandOptionChannel,
+	"role":    discordgo.ApplicationCommandOptionRole,
+}
+
+// SyncApplicationCommands registers every command as a global application command, replacing the existing ones.
+// Global commands can take a while to show up in discord, use SyncGuildApplicationCommands while developing.
+func (bot *Bot) SyncApplicationCommands() error {
+	return bot.SyncGuildApplicationCommands("")
+}
+
+// SyncGuildApplicationCommands registers every command as an application command in the guild with guildID, replacing the existing ones.
+func (bot *Bot) SyncGuildApplicationCommands(guildID string) error {
+	_, err := bot.Session.ApplicationCommandBulkOverwrite(bot.applicationID(), guildID, bot.ApplicationCommands())
+	return err
+}
+
+// ApplicationCommands returns the application command definitions for all commands.
+func (bot *Bot) ApplicationCommands() []*discordgo.ApplicationCommand {
+	names := make([]string, 0, len(bot.Commands))
+	for name := range bot.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	commands := make([]*discordgo.ApplicationCommand, 0, len(names))
+	for _, name := range names {
+		cmd := bot.Commands[name]
+		command := &discordgo.ApplicationCommand{
+			Name:        strings.ToLower(cmd.Name),
+			Description: applicationDescription(cmd.Description),
+			Options:     applicationOptions(cmd),
+		}
+		if cmd.GuildOnly {
+			dm := false
+			command.DMPermission = &dm
+		}
+		// Discord can only gate the top level command, subcommands needing more are still checked when they run.
+		if perms := cmd.requiredPermissions(); perms != 0 {
+			command.DefaultMemberPermissions = &perms
+		}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// Returns the options for cmd, subcommands become subcommand options and usage tags become typed options.
+// Discord only allows subcommands to be nested one level deep in groups.
+func applicationOptions(cmd *Command) []*discordgo.ApplicationCommandOption {
+	options := []*discordgo.ApplicationCommandOption{}
+
+	if len(cmd.Subcommands) > 0 {
+		names := make([]string, 0, len(cmd.Subcommands))
+		for name := range cmd.Subcommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub := cmd.Subcommands[name]
+			option := &discordgo.ApplicationCommandOption{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        strings.ToLower(sub.Name),
+				Description: applicationDescription(sub.Description),
+				Options:     applicationOptions(sub),
+			}
+			if len(sub.Subcommands) > 0 {
+				option.Type = discordgo.ApplicationCommandOptionSubCommandGroup
+			}
+			options = append(options, option)
+		}
+		return options
+	}
+
+	// Discord wants required options first, options are matched by name when invoked so the order doesn't matter to us.
+	var optionals []*discordgo.ApplicationCommandOption
+	for _, tag := range cmd.Usage {
+		description := cmd.ArgDescriptions[tag.Name]
+		if description == "" {
+			description = tag.Name
+		}
+		option := applicationOption(tag, applicationDescription(description))
+		if tag.Required {
+			options = append(options, option)
+		} else {
+			optionals = append(optionals, option)
+		}
+	}
+	// Declared flags are optional options named after the flag.
+	for _, flag := range cmd.Flags {
+		optionals = append(optionals, applicationOption(flag.tag, applicationDescription(flag.Description)))
+	}
+	return append(options, optionals...)
+}
+
+// Returns the typed option for tag.
+func applicationOption(tag *UsageTag, description string) *discordgo.ApplicationCommandOption {
+	option := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        applicationOptionName(tag),
+		Description: description,
+		Required:    tag.Required,
+	}
+	if typ, ok := applicationOptionTypes[tag.Type]; ok && !tag.Rest {
+		option.Type = typ
+	}
+	if len(tag.Choices) > 0 {
+		for _, choice := range tag.Choices {
+			option.Choices = append(option.Choices, &discordgo.ApplicationCommandOptionChoice{Name: choice, Value: choice})
+		}
+	} else if tag.Type == "literal" {
+		option.Choices = []*discordgo.ApplicationCommandOptionChoice{{Name: tag.Name, Value: tag.Name}}
+	}
+	if tag.Default != "" {
+		option.Description = applicationDescription(option.Description + " (default: " + tag.Default + ")")
+	}
+	// Discord can enforce the constraints too, rest strings are split later so their length can't be limited here.
+	switch option.Type {
+	case discordgo.ApplicationCommandOptionInteger, discordgo.ApplicationCommandOptionNumber:
+		option.MinValue = tag.Min
+		if tag.Max != nil {
+			option.MaxValue = *tag.Max
+		}
+	case discordgo.ApplicationCommandOptionString:
+		if tag.Min != nil && !tag.Rest {
+			min := int(*tag.Min)
+			option.MinLength = &min
+		}
+		if tag.Max != nil && !tag.Rest {
+			option.MaxLength = int(*tag.Max)
+		}
+	}
+	return option
+}
+
+// Returns the name of the option for tag, literals with choices are named after the choices. e.g <add|remove> is add-remove
+func applicationOptionName(tag *UsageTag) string {
+	return strings.ToLower(strings.ReplaceAll(tag.Name, "|", "-"))
+}
+
+// Discord requires descriptions to be between 1 and 100 characters, not bytes.
+func applicationDescription(description string) string {
+	if description == "" {
+		return "No Description Provided."
+	}
+	if runes := []rune(description); len(runes) > 100 {
+		return string(runes[:97]) + "..."
+	}
+	return description
+}
+
+// Returns the ID of the bot's application, it is the same as the bot user's ID.
+func (bot *Bot) applicationID() string {
+	if bot.Application != nil {
+		return bot.Application.ID
+	}
+	return bot.Session.State.User.ID
+}
+
+// Converts an option's value back to how it would've been typed in a message.
+func applicationOptionString(option *discordgo.ApplicationCommandInteractionDataOption) string {
+	switch option.Type {
+	case discordgo.ApplicationCommandOptionInteger:
+		return strconv.FormatInt(option.IntValue(), 10)
+	case discordgo.ApplicationCommandOptionNumber:
+		return strconv.FormatFloat(option.FloatValue(), 'f', -1, 64)
+	case discordgo.ApplicationCommandOptionBoolean:
+		return strconv.FormatBool(option.BoolValue())
+	default:
+		// Strings and IDs of users/channels/roles.
+		return option.StringValue()
+	}
+}
+
+func interactionListener(bot *Bot) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		bot.handleInteraction(i.Interaction)
+	}
+}
+
+// Runs the command invoked by an application command interaction.
+func (bot *Bot) handleInteraction(i *discordgo.Interaction) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			bot.ErrorHandler(bot, err)
+		}
+	}()
+
+	data := i.ApplicationCommandData()
+	cmd := bot.GetCommand(data.Name)
+	if cmd == nil {
+		return
+	}
+
+	// Walk down the subcommands like the command handler does with the arguments.
+	input := data.Name
+	options := data.Options
+	for len(options) == 1 && (options[0].Type == discordgo.ApplicationCommandOptionSubCommand ||
+		options[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup) {
+		sub := cmd.GetSubcommand(options[0].Name)
+		if sub == nil {
+			return
+		}
+		cmd = sub
+		input += " " + options[0].Name
+		options = options[0].Options
+	}
+
+	// Convert the options back to raw arguments in the order of the usage string.
+	values := make(map[string]string)
+	for _, option := range options {
+		values[option.Name] = applicationOptionString(option)
+	}
+	// Flags are options too, a bool flag that is on has it's name as the value like in messages.
+	flags := make(map[string]string)
+	for _, flag := range cmd.Flags {
+		value, ok := values[applicationOptionName(flag.tag)]
+		if !ok || (flag.tag.Type == "bool" && value == "false") {
+			continue
+		}
+		if flag.tag.Type == "bool" {
+			value = flag.Name
+		}
+		flags[flag.Name] = value
+	}
+	// The values are laid out in a made up content with their offsets so JoinedArgs works the same for both.
+	var content strings.Builder
+	var tokens []token
+	for _, tag := range cmd.Usage {
+		value := values[applicationOptionName(tag)]
+		if content.Len() > 0 {
+			content.WriteString(" ")
+		}
+		offset := content.Len()
+		content.WriteString(value)
+		if tag.Rest {
+			for _, tok := range tokenize(value) {
+				tok.start += offset
+				tok.end += offset
+				tokens = append(tokens, tok)
+			}
+		} else {
+			tokens = append(tokens, token{value: value, start: offset, end: offset + len(value)})
+		}
+	}
+	// Missing optionals at the end aren't arguments at all.
+	for len(tokens) > 0 && tokens[len(tokens)-1].value == "" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	args := make([]string, len(tokens))
+	for i, tok := range tokens {
+		args[i] = tok.value
+	}
+
+	author := i.User
+	if i.Member != nil {
+		author = i.Member.User
+	}
+
+	var guild *discordgo.Guild
+	if i.GuildID != "" {
+		g, err := bot.Session.State.Guild(i.GuildID)
+		if err != nil {
+			return
+		}
+		guild = g
+	}
+
+	channel, err := bot.Session.State.Channel(i.ChannelID)
+	if err != nil {
+		// DM channels aren't always cached.
+		if guild != nil {
+			return
+		}
+		channel = &discordgo.Channel{ID: i.ChannelID, Type: discordgo.ChannelTypeDM}
+	}
+
+	// Application commands have no message, we make one up so everything that expects a message keeps working.
+	timestamp, _ := discordgo.SnowflakeTimestamp(i.ID)
+	message := &discordgo.Message{
+		ID:        i.ID,
+		ChannelID: i.ChannelID,
+		GuildID:   i.GuildID,
+		Author:    author,
+		Member:    i.Member,
+		Content:   strings.TrimSpace("/" + input + " " + content.String()),
+		Timestamp: timestamp,
+	}
+
+	// Let discord know we received it, responses fill in this deferred response.
+	err = bot.Session.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		bot.ErrorHandler(bot, err)
+		return
+	}
+
+	cctx := &CommandContext{
+		Bot:         bot,
+		Command:     cmd,
+		Message:     message,
+		Channel:     channel,
+		Session:     bot.Session,
+		Author:      author,
+		RawArgs:     args,
+		Prefix:      "/",
+		Guild:       guild,
+		Flags:       flags,
+		InvokedName: input,
+		Interaction: i,
+		argContent:  content.String(),
+		argTokens:   tokens,
+	}
+
+	bot.runCommand(cctx)
+
+	// Nothing was sent, don't leave the user looking at a response that is thinking forever.
+	if cctx.responseID == "" {
+		bot.Session.InteractionResponseDelete(i)
+	}
+}
+
+// Responds to the interaction of this context, the first response fills in the deferred response.
+// After that the response is edited if edit is true otherwise a followup message is sent.
+func (ctx *CommandContext) respond(content *string, embeds []*discordgo.MessageEmbed, edit bool) (*discordgo.Message, error) {
+	if ctx.responseID == "" || edit {
+		var data = &discordgo.WebhookEdit{Content: content}
+		if embeds != nil {
+			data.Embeds = &embeds
+		}
+		msg, err := ctx.Session.InteractionResponseEdit(ctx.Interaction, data)
+		if err != nil {
+			return nil, err
+		}
+		ctx.responseID = msg.ID
+		return msg, nil
+	}
+	var data = &discordgo.WebhookParams{Embeds: embeds}
+	if content != nil {
+		data.Content = *content
+	}
+	return ctx.Session.FollowupMessageCreate(ctx.Interaction, true, data)
+}
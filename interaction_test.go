@@ -0,0 +1,164 @@
+package sapphire
+
+import (
+	"encoding/json"
+	"github.com/bwmarrin/discordgo"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+type standInRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// Redirects every request of the session to a local server.
+type standInTransport struct {
+	url *url.URL
+}
+
+func (t *standInTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.url.Scheme
+	req.URL.Host = t.url.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// Creates a bot talking to a local stand-in of the discord API that records every request and responds with a message.
+func newStandInBot(t *testing.T) (*Bot, func() []standInRequest) {
+	var lock sync.Mutex
+	var requests []standInRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lock.Lock()
+		requests = append(requests, standInRequest{Method: r.Method, Path: r.URL.Path, Body: string(body)})
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/commands") {
+			w.Write([]byte("[]"))
+			return
+		}
+		w.Write([]byte(`{"id":"900000000000000000","channel_id":"300000000000000000","timestamp":"2020-01-01T00:00:00Z"}`))
+	}))
+	t.Cleanup(server.Close)
+	serverURL, _ := url.Parse(server.URL)
+
+	bot := newTestBot(t)
+	bot.Session.Client = &http.Client{Transport: &standInTransport{url: serverURL}}
+	bot.Session.State.User = &discordgo.User{ID: "100000000000000000", Username: "sapphire"}
+	return bot, func() []standInRequest {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]standInRequest{}, requests...)
+	}
+}
+
+func TestSyncApplicationCommands(t *testing.T) {
+	bot, requests := newStandInBot(t)
+	noop := func(ctx *CommandContext) {}
+	bot.AddCommand(NewCommand("ban", "Moderation", noop).SetUsage("[days:int] <@user>").SetGuildOnly(true).
+		SetArgDescription("user", "The user to ban."))
+	bot.AddCommand(NewCommand("tag", "Tags", nil).
+		AddSubcommand(NewCommand("create", "", noop).SetUsage("<name:string> <content:string...>")))
+
+	if err := bot.SyncApplicationCommands(); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := requests()
+	if len(reqs) != 1 || reqs[0].Method != "PUT" || reqs[0].Path != "/api/v9/applications/100000000000000000/commands" {
+		t.Fatalf("Unexpected requests %v", reqs)
+	}
+	var commands []*discordgo.ApplicationCommand
+	if err := json.Unmarshal([]byte(reqs[0].Body), &commands); err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 2 || commands[0].Name != "ban" || commands[1].Name != "tag" {
+		t.Fatalf("Expected the ban and tag commands but got %v", commands)
+	}
+
+	ban := commands[0]
+	if ban.DMPermission == nil || *ban.DMPermission {
+		t.Error("Expected guild only commands to not be allowed in DMs")
+	}
+	if len(ban.Options) != 2 || ban.Options[0].Name != "user" || ban.Options[0].Type != discordgo.ApplicationCommandOptionUser || !ban.Options[0].Required ||
+		ban.Options[1].Name != "days" || ban.Options[1].Type != discordgo.ApplicationCommandOptionInteger || ban.Options[1].Required {
+		t.Errorf("Unexpected options for ban %+v %+v", ban.Options[0], ban.Options[1])
+	}
+	if ban.Options[0].Description != "The user to ban." || ban.Options[1].Description != "days" {
+		t.Errorf("Expected the options to be described by their description or name but got %q and %q", ban.Options[0].Description, ban.Options[1].Description)
+	}
+
+	tag := commands[1]
+	if len(tag.Options) != 1 || tag.Options[0].Type != discordgo.ApplicationCommandOptionSubCommand || tag.Options[0].Name != "create" ||
+		len(tag.Options[0].Options) != 2 {
+		t.Errorf("Expected tag to have the create subcommand but got %+v", tag.Options)
+	}
+}
+
+func TestInteractionRunsCommand(t *testing.T) {
+	bot, requests := newStandInBot(t)
+	var days int
+	var reason string
+	var force bool
+	bot.AddCommand(NewCommand("tag", "Tags", nil).
+		AddSubcommand(NewCommand("prune", "", func(ctx *CommandContext) {
+			days = ctx.Arg(0).AsInt()
+			reason = ctx.JoinedArgs(1)
+			force = ctx.FlagArg("force").AsBool()
+			ctx.Reply("Pruned.")
+		}).SetUsage("<days:int> [reason:string...]").AddFlag("force", "bool", "", "")))
+
+	bot.handleInteraction(&discordgo.Interaction{
+		ID:        "800000000000000000",
+		AppID:     "100000000000000000",
+		Type:      discordgo.InteractionApplicationCommand,
+		ChannelID: "300000000000000000",
+		Token:     "token",
+		User:      &discordgo.User{ID: "200000000000000000"},
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name: "tag",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{{
+				Name: "prune",
+				Type: discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{
+					{Name: "reason", Type: discordgo.ApplicationCommandOptionString, Value: "old tags"},
+					{Name: "days", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(7)},
+					{Name: "force", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+				},
+			}},
+		},
+	})
+
+	if days != 7 || reason != "old tags" || !force {
+		t.Errorf("Expected the options to be parsed as arguments and flags but got %d, \"%s\" and %t", days, reason, force)
+	}
+
+	reqs := requests()
+	if len(reqs) != 2 {
+		t.Fatalf("Expected a deferred response and an edit but got %v", reqs)
+	}
+	if reqs[0].Method != "POST" || reqs[0].Path != "/api/v9/interactions/800000000000000000/token/callback" || !strings.Contains(reqs[0].Body, `"type":5`) {
+		t.Errorf("Expected the interaction to be deferred but got %v", reqs[0])
+	}
+	if reqs[1].Method != "PATCH" || reqs[1].Path != "/api/v9/webhooks/100000000000000000/token/messages/@original" || !strings.Contains(reqs[1].Body, "Pruned.") {
+		t.Errorf("Expected the reply to edit the deferred response but got %v", reqs[1])
+	}
+}
+
+func TestApplicationDescription(t *testing.T) {
+	long := strings.Repeat("é", 120)
+	desc := applicationDescription(long)
+	if !utf8.ValidString(desc) || utf8.RuneCountInString(desc) != 100 || !strings.HasSuffix(desc, "...") {
+		t.Errorf("Expected the description to be cut to 100 characters but got %q", desc)
+	}
+	if desc := applicationDescription("Bans a member."); desc != "Bans a member." {
+		t.Errorf("Expected short descriptions to be kept but got %q", desc)
+	}
+}
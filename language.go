@@ -5,8 +5,9 @@ import (
 )
 
 type Language struct {
-	Name string
-	Keys map[string]string
+	Name  string
+	Keys  map[string]string
+	Rules *LocaleRules // Plural and formatting rules for strings with named placeholders. (default: found by Name)
 }
 
 // NewLanguage creates a new language with the specified name.
@@ -27,14 +28,45 @@ func (l *Language) Set(key string, value string) *Language {
 	return l
 }
 
+// SetRules sets the plural and formatting rules, only needed for locales sapphire doesn't know.
+func (l *Language) SetRules(rules *LocaleRules) *Language {
+	l.Rules = rules
+	return l
+}
+
+// Get returns the key formatted with args, it returns an empty string if the key doesn't exist.
+// Strings with named placeholders like {seconds} are formatted with the Params in the last argument, see Params.
+// Other strings are formatted with Sprintf and the Params are left out, so are strings with Printf verbs when no Params are passed.
 func (l *Language) Get(key string, args ...interface{}) string {
 	v, ok := l.Keys[key]
 	if ok {
-		return fmt.Sprintf(v, args...)
+		return l.Format(v, args...)
 	}
 	return ""
 }
 
+// Format formats message like Get formats keys.
+func (l *Language) Format(message string, args ...interface{}) string {
+	var params Params
+	if len(args) > 0 {
+		if p, ok := args[len(args)-1].(Params); ok {
+			params = p
+			args = args[:len(args)-1]
+		}
+	}
+	// Without Params braces are only placeholders if the arguments can't be for Printf verbs, e.g "{0} and {1}"
+	if !isMessageFormat(message) || params == nil && (len(args) == 0 || hasSprintfVerbs(message)) {
+		return fmt.Sprintf(message, args...)
+	}
+	rules := l.Rules
+	if rules == nil {
+		rules = rulesFor(l.Name)
+	}
+	// A broken string still shows what could be formatted, the loaders report syntax errors early.
+	formatted, _ := formatMessage(rules, message, args, params)
+	return formatted
+}
+
 func (l *Language) GetDefault(key string, def string, args ...interface{}) string {
 	v := l.Get(key, args...)
 	if v == "" {
@@ -56,5 +88,77 @@ var English = NewLanguage("en-US").
 	Set("COMMAND_INVITE", "To invite me to your server: <%s>").
 	Set("COMMAND_OWNER_ONLY", "This command is for the bot owner only!").
 	Set("COMMAND_GUILD_ONLY", "This command can only be used in a server!").
-	Set("COMMAND_COOLDOWN", "You can use this command again in %d seconds.").
-	Set("COMMAND_DISABLED", "This command has been disabled globally by the bot owner.")
+	Set("COMMAND_MISSING_PERMISSIONS", "You need the following permissions to use this command: **%s**").
+	Set("COMMAND_BOT_MISSING_PERMISSIONS", "I need the following permissions to perform this command: **%s**").
+	Set("COMMAND_SUBCOMMAND_REQUIRED", "Please specify one of the subcommands: **%s**").
+	Set("COMMAND_COOLDOWN", "You can use this command again in {seconds, plural, one {# second} other {# seconds}}.").
+	Set("COMMAND_DISABLED", "This command has been disabled globally by the bot owner.").
+	Set("COMMAND_HELP_UNKNOWN", "Unknown Command.").
+	Set("COMMAND_HELP_TITLE", "Command Help").
+	Set("COMMAND_HELP_DESCRIPTION", "**Name:** %s\n**Description:** %s\n**Category:** %s\n**Aliases:** %s\n**Usage:** %s").
+	Set("COMMAND_HELP_NO_ALIASES", "None").
+	Set("COMMAND_HELP_FLAGS", "**Flags:**").
+	Set("COMMAND_HELP_SUBCOMMANDS", "**Subcommands:**").
+	Set("COMMAND_HELP_DEFAULT", "(default: %s)").
+	Set("COMMAND_HELP_COMMANDS", "Commands").
+	Set("COMMAND_HELP_FOOTER", "For more info on a command use: %s").
+	Set("COMMAND_STATS_TITLE", "Stats").
+	Set("COMMAND_STATS_GO_VERSION", "Go Version").
+	Set("COMMAND_STATS_DISCORDGO_VERSION", "DiscordGo Version").
+	Set("COMMAND_STATS_SAPPHIRE_VERSION", "Sapphire Version").
+	Set("COMMAND_STATS_BOT_TITLE", "Bot Stats").
+	Set("COMMAND_STATS_BOT", "**Guilds:** %d\n**Users:** %d\n**Channels:** %d\n**Uptime:** %s").
+	Set("COMMAND_STATS_COMMANDS_TITLE", "Command Stats").
+	Set("COMMAND_STATS_COMMANDS", "**Total Commands:** %d\n**Commands Ran:** %d").
+	Set("COMMAND_STATS_MEMORY_TITLE", "Memory Stats").
+	Set("COMMAND_STATS_MEMORY", "**Used:** %s / %s\n**Garbage Collected:** %s\n**GC Cycles:** %d\n**Forced GC Cycles:** %d\n**Last GC:** %s\n**Next GC Target:** %s\n**Goroutines:** %d").
+	Set("COMMAND_STATS_TECHNICAL_TITLE", "Technical Info").
+	Set("COMMAND_STATS_TECHNICAL", "**CPU Cores:** %d\n**OS/Arch:** %s/%s").
+	Set("COMMAND_GC", "Forced Garbage Collection.\n  - Freed **%s**\n  - %d Objects Collected.\n  - Took **%d**μs").
+	Set("PAGINATOR_FOOTER", "Page %d/%d").
+	Set("ARGUMENT_REQUIRED", "The argument **%s** is required.").
+	Set("ARGUMENT_TYPE_INVALID", "The argument type '%s' is invalid.").
+	Set("ARGUMENT_INT", "**%s** must be a valid whole number.").
+	Set("ARGUMENT_MEMBER", "**%s** must be a valid member mention, ID or name.").
+	Set("ARGUMENT_MEMBER_NOT_FOUND", "That member cannot be found in this server.").
+	Set("ARGUMENT_USER", "**%s** must be a valid user mention, ID or name.").
+	Set("ARGUMENT_USER_NOT_FOUND", "That user cannot be found.").
+	Set("ARGUMENT_CHANNEL", "**%s** must be a valid channel mention, ID or name.").
+	Set("ARGUMENT_CHANNEL_NOT_FOUND", "That channel cannot be found.").
+	Set("ARGUMENT_LITERAL", "Literal argument must be **%s**").
+	Set("ARGUMENT_CHOICES", "**%s** must be one of: **%s**").
+	Set("ARGUMENT_MIN", "**%s** must be at least %s.").
+	Set("ARGUMENT_MAX", "**%s** must be at most %s.").
+	Set("ARGUMENT_RANGE", "**%s** must be between %s and %s.").
+	Set("ARGUMENT_EXACT", "**%s** must be %s.").
+	Set("ARGUMENT_LENGTH_MIN", "**%s** must be at least %s characters long.").
+	Set("ARGUMENT_LENGTH_MAX", "**%s** must be at most %s characters long.").
+	Set("ARGUMENT_LENGTH_RANGE", "**%s** must be between %s and %s characters long.").
+	Set("ARGUMENT_LENGTH_EXACT", "**%s** must be exactly %s characters long.").
+	Set("ARGUMENT_FLOAT", "**%s** must be a valid number.").
+	Set("ARGUMENT_BOOL", "**%s** must be one of yes, no, on, off, true or false.").
+	Set("ARGUMENT_GUILD_ONLY", "**%s** can only be used in a server.").
+	Set("ARGUMENT_ROLE", "**%s** must be a valid role mention, ID or name.").
+	Set("ARGUMENT_ROLE_NOT_FOUND", "That role cannot be found in this server.").
+	Set("ARGUMENT_GUILD", "**%s** must be a valid server ID or name.").
+	Set("ARGUMENT_GUILD_NOT_FOUND", "That server cannot be found.").
+	Set("ARGUMENT_MESSAGE", "**%s** must be a valid message ID or link.").
+	Set("ARGUMENT_MESSAGE_NOT_FOUND", "That message cannot be found.").
+	Set("ARGUMENT_DURATION", "**%s** must be a valid duration like 1h30m, 2d or 1 week.").
+	Set("ARGUMENT_TIME", "**%s** must be a valid time like 2024-01-31 18:00, tomorrow or in 2 hours.").
+	Set("ARGUMENT_EMOJI", "**%s** must be a valid emoji.").
+	Set("ARGUMENT_URL", "**%s** must be a valid link.").
+	Set("ARGUMENT_TOO_MANY_MATCHES", "Too many matches were found for **%s**, please be more specific.").
+	Set("ARGUMENT_WHICH_ONE", "Multiple matches were found for **%s**, which one did you mean? Reply with the number or `cancel`.%s").
+	Set("ARGUMENT_INVALID_CHOICE", "That isn't one of the choices.").
+	Set("PROMPT_TIMEOUT", "You took too long to answer.").
+	Set("PROMPT_CANCELLED", "Cancelled.").
+	Set("PROMPT_CANCEL_KEYWORD", "cancel").
+	Set("PROMPT_ARGUMENT", "Please provide **%s**, reply with `cancel` to cancel.").
+	Set("PROMPT_RETRY", "%s Please try again or reply with `cancel` to cancel.").
+	Set("FLAG_INVALID", "The flag **%s** must be a valid %s.").
+	Set("FLAG_UNKNOWN", "Unknown flags: **%s**").
+	Set("FLAG_VALUE_REQUIRED", "The flag **--%s** needs a value.").
+	Set("COMMAND_SUGGESTION", "Did you mean %s?").
+	Set("COMMAND_SUGGESTION_CONFIRM", "Did you mean %s? React with %s to run it.").
+	Set("COMMAND_UNKNOWN_SUGGESTION", "Unknown Command. Did you mean %s?")
@@ -0,0 +1,294 @@
+package sapphire
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Language files are json, yaml or toml files named after their locale, e.g languages/fr-FR.yaml
+// Keys can be nested to group them, nested keys are joined with an underscore so this yaml
+//
+//	COMMAND:
+//	  PING: Pong!
+//
+// sets the key COMMAND_PING
+
+// LoadLanguage loads a language from a json, yaml or toml file, the name of the language is the file name without the extension.
+// The format verbs of keys that the builtin English language has are checked to match it so a translation can't break the builtin replies.
+func LoadLanguage(file string) (*Language, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseLanguage(filepath.Base(file), data)
+}
+
+// LoadLanguagesFS loads every language file in dir of fsys, files with other extensions are skipped.
+// It's useful with an embed.FS to ship the languages inside the binary, or os.DirFS to load them from disk at startup.
+func LoadLanguagesFS(fsys fs.FS, dir string) ([]*Language, error) {
+	return readLanguagesFS(fsys, dir, parseLanguage)
+}
+
+// DecodeLanguagesFS is like LoadLanguagesFS without checking the format verbs, see DecodeLanguage.
+func DecodeLanguagesFS(fsys fs.FS, dir string) ([]*Language, error) {
+	return readLanguagesFS(fsys, dir, DecodeLanguage)
+}
+
+// Reads every language file in dir of fsys with parse.
+func readLanguagesFS(fsys fs.FS, dir string, parse func(name string, data []byte) (*Language, error)) ([]*Language, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var languages []*Language
+	for _, entry := range entries {
+		if entry.IsDir() || !isLanguageFile(entry.Name()) {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		lang, err := parse(entry.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+		languages = append(languages, lang)
+	}
+	return languages, nil
+}
+
+func isLanguageFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
+
+// Parses a language file by it's extension and checks it against English.
+func parseLanguage(name string, data []byte) (*Language, error) {
+	lang, err := DecodeLanguage(name, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVerbs(lang, English); err != nil {
+		return nil, fmt.Errorf("The language file %s is invalid: %v", name, err)
+	}
+	return lang, nil
+}
+
+// DecodeLanguage decodes the json, yaml or toml data of the language file name without checking the format verbs,
+// it's for tools that report them like AuditLanguage, bots should use LoadLanguage.
+func DecodeLanguage(name string, data []byte) (*Language, error) {
+	ext := path.Ext(name)
+	values := make(map[string]interface{})
+	var err error
+	switch strings.ToLower(ext) {
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("The language file %s isn't a json, yaml or toml file.", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("The language file %s is invalid: %v", name, err)
+	}
+
+	lang := NewLanguage(strings.TrimSuffix(name, ext))
+	if err := flattenKeys(lang, "", values); err != nil {
+		return nil, fmt.Errorf("The language file %s is invalid: %v", name, err)
+	}
+	return lang, nil
+}
+
+// Sets the keys of values in lang, nested keys are joined to their parent's key with an underscore.
+func flattenKeys(lang *Language, prefix string, values map[string]interface{}) error {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch v := value.(type) {
+		case string:
+			lang.Set(key, v)
+		case map[string]interface{}:
+			if err := flattenKeys(lang, key, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("the key %s must be a string or a group of keys.", key)
+		}
+	}
+	return nil
+}
+
+// Returns an error for the first key, in alphabetical order, which uses different format verbs than the same key in reference
+// or has a broken named placeholder.
+func checkVerbs(lang, reference *Language) error {
+	keys := make([]string, 0, len(lang.Keys))
+	for key := range lang.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := checkKey(key, lang.Keys[key], reference.Keys[key]); err != nil {
+			return fmt.Errorf("the key %s %v", key, err)
+		}
+	}
+	return nil
+}
+
+// The positional arguments the framework passes with the builtin keys that English formats with named placeholders.
+var positionalArguments = map[string]string{
+	"COMMAND_COOLDOWN": "%d",
+}
+
+// Checks a translated value against the reference value of the same key, reference is empty if it doesn't have the key.
+func checkKey(key, value, reference string) error {
+	// Params are passed for keys using named placeholders in the reference, without them a string with verbs is Printf.
+	if isMessageFormat(value) && (isMessageFormat(reference) || !hasSprintfVerbs(value)) {
+		if err := checkMessage(value); err != nil {
+			return fmt.Errorf("is invalid: %v", err)
+		}
+		// With Params the whole string uses the message format so it's verbs are never formatted.
+		if hasSprintfVerbs(value) {
+			return errors.New("mixes format verbs like %s with named placeholders like {name}, use only one of them.")
+		}
+		if isMessageFormat(reference) {
+			return comparePlaceholders(value, reference)
+		}
+		return nil
+	}
+	// Named placeholders are passed with positional arguments too so their number can't be known, unless it's a builtin key.
+	if isMessageFormat(reference) {
+		reference = positionalArguments[key]
+	}
+	if reference == "" {
+		return nil
+	}
+	return compareVerbs(value, reference)
+}
+
+// Returns an error if message doesn't use the same named placeholders as reference.
+// Positional placeholders like {0} are always passed so a translation can use them even if the reference doesn't.
+func comparePlaceholders(message, reference string) error {
+	want := make(map[string]bool)
+	for _, name := range placeholderNames(reference) {
+		want[name] = true
+	}
+	got := make(map[string]bool)
+	for _, name := range placeholderNames(message) {
+		got[name] = true
+		if _, err := strconv.Atoi(name); !want[name] && err != nil {
+			return fmt.Errorf("uses the placeholder {%s} which isn't in the reference.", name)
+		}
+	}
+	for _, name := range placeholderNames(reference) {
+		if !got[name] {
+			return fmt.Errorf("doesn't use the placeholder {%s}.", name)
+		}
+	}
+	return nil
+}
+
+// A format verb in a language string and the argument it formats, e.g %s or %[2]d
+type formatVerb struct {
+	arg  int  // Index of the argument starting at 0.
+	verb rune // The verb, * for a width or precision taken from the arguments.
+}
+
+// Returns the verbs in format in the order fmt reads them, explicit argument indexes like %[2]s are supported.
+func formatVerbs(format string) []formatVerb {
+	var verbs []formatVerb
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		// Flags, width, precision and argument indexes.
+		for i < len(format) && strings.IndexByte("+-# 0123456789.*[", format[i]) >= 0 {
+			switch format[i] {
+			case '[':
+				end := strings.IndexByte(format[i:], ']')
+				if end < 0 {
+					return verbs
+				}
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil {
+					arg = n - 1
+				}
+				i += end
+			case '*':
+				verbs = append(verbs, formatVerb{arg, '*'})
+				arg++
+			}
+			i++
+		}
+		if i >= len(format) || format[i] == '%' {
+			continue
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		verbs = append(verbs, formatVerb{arg, verb})
+		arg++
+		i += size - 1
+	}
+	return verbs
+}
+
+// Groups verbs by the type of value they expect, v formats anything.
+func verbKind(verb rune) string {
+	switch verb {
+	case 's', 'q':
+		return "text"
+	case 'd', 'b', 'o', 'x', 'X', 'c', 'U', '*':
+		return "integer"
+	case 'f', 'F', 'e', 'E', 'g', 'G':
+		return "number"
+	case 't':
+		return "bool"
+	case 'v':
+		return "any"
+	}
+	return "%" + string(verb)
+}
+
+// Returns an error if format doesn't use the same arguments as reference with compatible verbs.
+func compareVerbs(format, reference string) error {
+	kinds := func(s string) map[int]string {
+		m := make(map[int]string)
+		for _, v := range formatVerbs(s) {
+			if kind, ok := m[v.arg]; !ok || kind == "any" {
+				m[v.arg] = verbKind(v.verb)
+			}
+		}
+		return m
+	}
+	got, want := kinds(format), kinds(reference)
+	if len(got) != len(want) {
+		return fmt.Errorf("uses %d format arguments instead of %d.", len(got), len(want))
+	}
+	for arg, kind := range want {
+		other, ok := got[arg]
+		if !ok {
+			return fmt.Errorf("doesn't use the format argument %d.", arg+1)
+		}
+		if other != kind && other != "any" && kind != "any" {
+			return fmt.Errorf("formats the argument %d as %s instead of %s.", arg+1, other, kind)
+		}
+	}
+	return nil
+}
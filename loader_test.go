@@ -0,0 +1,135 @@
+package sapphire
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadLanguagesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"languages/de-DE.json": {Data: []byte(`{"COMMAND": {"PING": "Pong!", "COOLDOWN": "Warte noch %d Sekunden."}}`)},
+		"languages/fr-FR.yaml": {Data: []byte("COMMAND:\n  PING: Pong !\nHELLO: Bonjour\n")},
+		"languages/es-MX.toml": {Data: []byte("HELLO = \"Hola\"\n[COMMAND]\nNOT_FOUND = \"No se encontró el comando '%s'.\"\n")},
+		"languages/README.md":  {Data: []byte("# Translations")},
+	}
+
+	languages, err := LoadLanguagesFS(fsys, "languages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(languages) != 3 {
+		t.Fatalf("Expected 3 languages but got %d", len(languages))
+	}
+
+	expected := map[string]map[string]string{
+		"de-DE": {"COMMAND_PING": "Pong!", "COMMAND_COOLDOWN": "Warte noch %d Sekunden."},
+		"fr-FR": {"COMMAND_PING": "Pong !", "HELLO": "Bonjour"},
+		"es-MX": {"HELLO": "Hola", "COMMAND_NOT_FOUND": "No se encontró el comando '%s'."},
+	}
+	for _, lang := range languages {
+		keys, ok := expected[lang.Name]
+		if !ok {
+			t.Errorf("Unexpected language %s", lang.Name)
+			continue
+		}
+		if len(lang.Keys) != len(keys) {
+			t.Errorf("Expected %s to have %v but got %v", lang.Name, keys, lang.Keys)
+		}
+		for key, value := range keys {
+			if lang.Keys[key] != value {
+				t.Errorf("Expected %s in %s to be %q but got %q", key, lang.Name, value, lang.Keys[key])
+			}
+		}
+	}
+}
+
+func TestLoadLanguageErrors(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"verbs.json":    `{"COMMAND_NOT_FOUND": "Kommando %d nicht gefunden."}`,
+		"missing.json":  `{"COMMAND_NOT_FOUND": "Kommando nicht gefunden."}`,
+		"extra.yaml":    "COMMAND_PING: \"%s\"",
+		"list.yaml":     "COMMAND_PING: [a, b]",
+		"broken.toml":   "COMMAND_PING = ",
+		"language.txt":  "COMMAND_PING",
+		"cooldown.json": `{"COMMAND_COOLDOWN": "Warte noch %.1f Sekunden."}`,
+		"renamed.json":  `{"COMMAND_COOLDOWN": "Warte noch {secs} Sekunden."}`,
+	}
+	for name, content := range cases {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLanguage(file); err == nil {
+			t.Errorf("Expected loading %s to fail", name)
+		}
+	}
+
+	file := filepath.Join(dir, "pt-BR.yml")
+	os.WriteFile(file, []byte("COMMAND_NOT_FOUND: \"O comando '%[1]s' não foi encontrado.\""), 0644)
+	lang, err := LoadLanguage(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lang.Name != "pt-BR" || lang.Get("COMMAND_NOT_FOUND", "foo") != "O comando 'foo' não foi encontrado." {
+		t.Errorf("Unexpected language %s %v", lang.Name, lang.Keys)
+	}
+}
+
+func TestDecodeLanguagesFS(t *testing.T) {
+	fsys := fstest.MapFS{"de.json": {Data: []byte(`{"COMMAND_PING": "Pong %s"}`)}}
+	if _, err := LoadLanguagesFS(fsys, "."); err == nil {
+		t.Error("Expected loading to check the format verbs")
+	}
+	languages, err := DecodeLanguagesFS(fsys, ".")
+	if err != nil || len(languages) != 1 || languages[0].Keys["COMMAND_PING"] != "Pong %s" {
+		t.Errorf("Expected decoding to not check the format verbs but got %v, %v", languages, err)
+	}
+}
+
+func TestCompareVerbs(t *testing.T) {
+	cases := []struct {
+		format, reference string
+		err               string
+	}{
+		{"%s und %d", "%s and %d", ""},
+		{"%[2]d, %[1]s", "%s and %d", ""},
+		{"%v %.1f %%", "%s %.2f", ""},
+		{"%s", "%s and %d", "uses 1 format arguments instead of 2."},
+		{"%d", "%s", "formats the argument 1 as integer instead of text."},
+		{"%s %[3]s", "%s %s", "doesn't use the format argument 2."},
+	}
+
+	for _, c := range cases {
+		err := compareVerbs(c.format, c.reference)
+		if (err == nil) != (c.err == "") || (err != nil && !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("compareVerbs(%q, %q) = %v, expected %q", c.format, c.reference, err, c.err)
+		}
+	}
+}
+
+func TestComparePlaceholders(t *testing.T) {
+	reference := "{name} has {count, plural, one {# {kind}} other {# {kind}s}}"
+	cases := []struct {
+		message string
+		err     string
+	}{
+		{"{count, plural, one {un {kind}} other {# {kind}s}} pour {name}", ""},
+		{"{name}: {count} {kind} ({0})", ""},
+		{"{name} hat {count} Sachen", "doesn't use the placeholder {kind}."},
+		{"{user} has {count} {kind}", "uses the placeholder {user} which isn't in the reference."},
+	}
+
+	for _, c := range cases {
+		err := comparePlaceholders(c.message, reference)
+		if (err == nil) != (c.err == "") || (err != nil && err.Error() != c.err) {
+			t.Errorf("comparePlaceholders(%q) = %v, expected %q", c.message, err, c.err)
+		}
+	}
+	if err := checkKey("COMMAND_COOLDOWN", "Encore {0} secondes.", English.Keys["COMMAND_COOLDOWN"]); err == nil {
+		t.Error("Expected a translation without {seconds} to be reported")
+	}
+}
@@ -0,0 +1,100 @@
+package sapphire
+
+import (
+	"strings"
+)
+
+// Locales are BCP-47 tags like pt-BR or zh-Hant-TW, when a key isn't found in a language it's looked up in the next
+// language of the locale's chain: the locale, it's fallbacks set with SetLocaleFallbacks, it's parents and then the default locales.
+// e.g with es-MX, es and en-US registered and en-US as the default, es-MX tries es-MX then es then en-US.
+
+// Returns the locale in a form that can be compared, locales are case insensitive and _ is accepted instead of -
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// Returns the parents of a locale, closest first. e.g zh-Hant-TW gives zh-Hant and zh
+func localeParents(locale string) []string {
+	parts := strings.Split(normalizeLocale(locale), "-")
+	parents := make([]string, 0, len(parts)-1)
+	for i := len(parts) - 1; i > 0; i-- {
+		parents = append(parents, strings.Join(parts[:i], "-"))
+	}
+	return parents
+}
+
+// GetLanguage returns the registered language called locale or nil if not found, names are compared case insensitively.
+func (bot *Bot) GetLanguage(locale string) *Language {
+	if lang, ok := bot.Languages[locale]; ok {
+		return lang
+	}
+	locale = normalizeLocale(locale)
+	for name, lang := range bot.Languages {
+		if normalizeLocale(name) == locale {
+			return lang
+		}
+	}
+	return nil
+}
+
+// Returns the first registered language of locale's chain without the default locales, nil if there is none.
+// e.g pt-BR is pt if only pt is registered.
+func (bot *Bot) findLanguage(locale string) *Language {
+	if chain := bot.localeChain(locale, nil, make(map[string]bool)); len(chain) > 0 {
+		return chain[0]
+	}
+	return nil
+}
+
+// LocaleChain returns the registered languages to look up keys in for locale, in order.
+// It's the locale, it's fallbacks, it's parents (pt-BR falls back to pt) and then the default locales.
+func (bot *Bot) LocaleChain(locale string) []*Language {
+	chain := bot.localeChain(locale, nil, make(map[string]bool))
+	// DefaultLocale can be assigned directly without SetDefaultLocale, it comes first then.
+	if def := bot.DefaultLocale; def != nil && (len(bot.DefaultLocales) == 0 || bot.GetLanguage(bot.DefaultLocales[0]) != def) {
+		if !containsLanguage(chain, def) {
+			chain = append(chain, def)
+		}
+		chain = bot.localeChain(def.Name, chain, make(map[string]bool))
+	}
+	for _, def := range bot.DefaultLocales {
+		chain = bot.localeChain(def, chain, make(map[string]bool))
+	}
+	return chain
+}
+
+// Appends the languages of locale, it's fallbacks and it's parents to chain skipping the ones already in it.
+func (bot *Bot) localeChain(locale string, chain []*Language, seen map[string]bool) []*Language {
+	key := normalizeLocale(locale)
+	if seen[key] {
+		return chain
+	}
+	seen[key] = true
+
+	if lang := bot.GetLanguage(locale); lang != nil && !containsLanguage(chain, lang) {
+		chain = append(chain, lang)
+	}
+	for _, fallback := range bot.LocaleFallbacks[key] {
+		chain = bot.localeChain(fallback, chain, seen)
+	}
+	for _, parent := range localeParents(locale) {
+		chain = bot.localeChain(parent, chain, seen)
+	}
+	return chain
+}
+
+func containsLanguage(chain []*Language, lang *Language) bool {
+	for _, l := range chain {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLocaleFallbacks sets the locales to try after locale before it's parents and the default locales.
+// e.g SetLocaleFallbacks("es-MX", "es-419") makes es-MX try es-MX, es-419 then es.
+func (bot *Bot) SetLocaleFallbacks(locale string, fallbacks ...string) *Bot {
+	bot.LocaleFallbacks[normalizeLocale(locale)] = fallbacks
+	return bot
+}
@@ -0,0 +1,95 @@
+package sapphire
+
+import (
+	"testing"
+)
+
+func languageNames(chain []*Language) []string {
+	names := make([]string, len(chain))
+	for i, lang := range chain {
+		names[i] = lang.Name
+	}
+	return names
+}
+
+func TestLocaleChain(t *testing.T) {
+	bot := newTestBot(t)
+	for _, name := range []string{"pt", "pt-BR", "es", "es-419", "es-MX", "zh", "zh-Hant"} {
+		bot.AddLanguage(NewLanguage(name))
+	}
+	bot.SetLocaleFallbacks("es-MX", "es-419")
+
+	tests := []struct {
+		locale   string
+		expected []string
+	}{
+		{"pt-BR", []string{"pt-BR", "pt", "en-US"}},
+		{"pt_br", []string{"pt-BR", "pt", "en-US"}},
+		{"pt-PT", []string{"pt", "en-US"}},
+		{"es-MX", []string{"es-MX", "es-419", "es", "en-US"}},
+		{"zh-Hant-TW", []string{"zh-Hant", "zh", "en-US"}},
+		{"en-US", []string{"en-US"}},
+		{"fr-FR", []string{"en-US"}},
+	}
+	for _, test := range tests {
+		got := languageNames(bot.LocaleChain(test.locale))
+		if len(got) != len(test.expected) {
+			t.Errorf("Expected the chain of %s to be %v but got %v", test.locale, test.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expected[i] {
+				t.Errorf("Expected the chain of %s to be %v but got %v", test.locale, test.expected, got)
+				break
+			}
+		}
+	}
+
+	bot.SetDefaultLocale("es", "en-US")
+	if got := languageNames(bot.LocaleChain("pt-BR")); len(got) != 4 || got[2] != "es" || got[3] != "en-US" {
+		t.Errorf("Expected the chain to end with the default locales but got %v", got)
+	}
+}
+
+func TestLocalizeFallback(t *testing.T) {
+	bot := newTestBot(t)
+	bot.AddLanguage(NewLanguage("es").Set("HELLO", "Hola").Set("BYE", "Adiós"))
+	bot.AddLanguage(NewLanguage("es-MX").Set("HELLO", "Qué onda"))
+
+	ctx := &CommandContext{Bot: bot, Locale: bot.Languages["es-MX"]}
+	tests := map[string]string{
+		"HELLO":         "Qué onda",
+		"BYE":           "Adiós",
+		"COMMAND_PING":  "Pong!",
+		"NOT_A_KEY_YET": English.Get("LOCALE_NO_KEY", "NOT_A_KEY_YET"),
+	}
+	for key, expected := range tests {
+		if got := ctx.Localize(key); got != expected {
+			t.Errorf("Expected %s to be %q but got %q", key, expected, got)
+		}
+	}
+}
+
+func TestSetDefaultLocaleUnknown(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected SetDefaultLocale to panic for an unknown language")
+		}
+	}()
+	newTestBot(t).SetDefaultLocale("es", "en-US")
+}
+
+func TestDefaultLocaleField(t *testing.T) {
+	bot := newTestBot(t)
+	// Assigned directly like before SetDefaultLocale took a chain, the language doesn't even have to be registered.
+	bot.DefaultLocale = NewLanguage("fr").Set("HELLO", "Bonjour")
+	bot.AddLanguage(NewLanguage("de"))
+
+	ctx := &CommandContext{Bot: bot, Locale: bot.Languages["de"]}
+	if got := ctx.Localize("HELLO"); got != "Bonjour" {
+		t.Errorf("Expected the assigned default locale to be used but got %q", got)
+	}
+	if got := languageNames(bot.LocaleChain("de")); len(got) != 3 || got[1] != "fr" || got[2] != "en-US" {
+		t.Errorf("Expected the chain to be de, fr, en-US but got %v", got)
+	}
+}
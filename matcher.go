@@ -0,0 +1,212 @@
+package sapphire
+
+import (
+	"strings"
+)
+
+// Matches the raw arguments to the usage of a command.
+// Optional arguments are skipped when the argument doesn't parse for them or when the arguments after them
+// can't be matched without skipping, e.g for [@user] <reason:string...> the reason can start right away.
+// Multi word types try their longest span first and unions try their types in order.
+type argumentMatcher struct {
+	ctx     *CommandContext
+	tags    []*UsageTag
+	parsed  map[[3]int]parsedArgument // Parsed arguments by tag, position and number of raw arguments.
+	failed  map[[2]int]bool           // Tags and positions that can't be matched.
+	failure *matchFailure             // The furthest failure, it's what gets reported.
+	aborted error                     // A prompt was cancelled while parsing, nothing else should be tried.
+}
+
+type parsedArgument struct {
+	arg *Argument
+	err error
+}
+
+// Where matching failed, err is nil if a required argument is missing.
+type matchFailure struct {
+	tag int
+	pos int
+	n   int // The number of raw arguments that failed.
+	err error
+}
+
+func newArgumentMatcher(ctx *CommandContext) *argumentMatcher {
+	return &argumentMatcher{
+		ctx:    ctx,
+		tags:   ctx.Command.Usage,
+		parsed: make(map[[3]int]parsedArgument),
+		failed: make(map[[2]int]bool),
+	}
+}
+
+// Matches the tags from i on to the raw arguments from pos on.
+// Every tag gets an argument, rest tags get one for every raw argument left. Extra raw arguments are ignored.
+func (m *argumentMatcher) match(i, pos int) ([]*Argument, bool) {
+	if m.aborted != nil {
+		return nil, false
+	}
+	if i == len(m.tags) {
+		return []*Argument{}, true
+	}
+	if m.failed[[2]int{i, pos}] {
+		return nil, false
+	}
+
+	args, ok := m.matchTag(i, pos)
+	if !ok {
+		m.failed[[2]int{i, pos}] = true
+	}
+	return args, ok
+}
+
+func (m *argumentMatcher) matchTag(i, pos int) ([]*Argument, bool) {
+	raw := m.ctx.RawArgs
+	tag := m.tags[i]
+
+	// Empty arguments are missing optionals from application commands.
+	if pos >= len(raw) || raw[pos] == "" {
+		// Skip the empty argument if it's there.
+		n := 0
+		if pos < len(raw) {
+			n = 1
+		}
+		if tag.Required {
+			m.fail(i, pos, n, nil)
+			return nil, false
+		}
+		// Not provided, ParseArgument fills in the default.
+		arg, err := m.parse(i, pos, 0)
+		if err != nil {
+			m.fail(i, pos, n, err)
+			return nil, false
+		}
+		return m.then(arg, i, pos+n)
+	}
+
+	if tag.Rest {
+		args := make([]*Argument, 0, len(raw)-pos)
+		for k := pos; k < len(raw); k++ {
+			arg, err := m.parse(i, k, 1)
+			if _, ok := err.(*ambiguousName); ok {
+				// Fail on the ambiguous word alone so only it is asked about.
+				m.fail(i, k, 1, err)
+				return nil, false
+			}
+			if err != nil {
+				// The rest fails as a whole.
+				m.fail(i, pos, len(raw)-pos, err)
+				return nil, false
+			}
+			args = append(args, arg)
+		}
+		if err := checkRestLength(m.ctx, tag, args); err != nil {
+			m.fail(i, pos, len(raw)-pos, err)
+			return nil, false
+		}
+		return args, true
+	}
+
+	for _, n := range m.spans(i, pos) {
+		arg, err := m.parse(i, pos, n)
+		if err != nil {
+			m.fail(i, pos, n, err)
+			if m.aborted != nil {
+				return nil, false
+			}
+			continue
+		}
+		if args, ok := m.then(arg, i, pos+n); ok {
+			return args, true
+		}
+	}
+
+	// Try the argument with the next tag instead, unless this is the last tag and the argument would be ignored.
+	// Application command options are matched by name so they never shift.
+	if !tag.Required && i+1 < len(m.tags) && m.ctx.Interaction == nil {
+		if arg, err := m.parse(i, pos, 0); err == nil {
+			return m.then(arg, i, pos)
+		}
+	}
+	return nil, false
+}
+
+// Matches the tags after i from pos and puts arg in front of them.
+func (m *argumentMatcher) then(arg *Argument, i, pos int) ([]*Argument, bool) {
+	args, ok := m.match(i+1, pos)
+	if !ok {
+		return nil, false
+	}
+	return append([]*Argument{arg}, args...), true
+}
+
+// Returns how many raw arguments the tag at i can take from pos, longest first.
+// Only multi word types can take more than one and they leave enough for the required tags after them.
+func (m *argumentMatcher) spans(i, pos int) []int {
+	if !m.tags[i].multiWord() || m.ctx.Interaction != nil {
+		return []int{1}
+	}
+	longest := len(m.ctx.RawArgs) - pos
+	for _, next := range m.tags[i+1:] {
+		if next.Required {
+			longest--
+		}
+	}
+	var spans []int
+	for n := longest; n > 1; n-- {
+		spans = append(spans, n)
+	}
+	return append(spans, 1)
+}
+
+// Parses n raw arguments from pos for the tag at i, the results are kept so nothing is parsed or prompted twice.
+func (m *argumentMatcher) parse(i, pos, n int) (*Argument, error) {
+	key := [3]int{i, pos, n}
+	res, ok := m.parsed[key]
+	if !ok {
+		raw := ""
+		if n > 0 {
+			raw = strings.Join(m.ctx.RawArgs[pos:pos+n], " ")
+		}
+		res.arg, res.err = ParseArgument(m.ctx, m.tags[i], raw)
+		m.parsed[key] = res
+	}
+	if _, abort := res.err.(*promptAbort); abort {
+		m.aborted = res.err
+	}
+	return res.arg, res.err
+}
+
+// Records a failure if it's further than the current one.
+// On ties the earlier tag wins, and for the same tag the shorter span wins since it's tried last
+// unless the longer one was an ambiguous name, that's still asked about.
+func (m *argumentMatcher) fail(i, pos, n int, err error) {
+	if _, ok := err.(*ambiguousName); !ok && m.failure != nil && m.failure.pos == pos && m.failure.tag == i {
+		if _, ambiguous := m.failure.err.(*ambiguousName); ambiguous {
+			return
+		}
+	}
+	if m.failure == nil || pos > m.failure.pos || (pos == m.failure.pos && i == m.failure.tag) {
+		m.failure = &matchFailure{tag: i, pos: pos, n: n, err: err}
+	}
+}
+
+// Keeps the arguments answered to a prompt for the tag at i, they replaced the raw arguments that failed at pos.
+// Matching again uses them as is without parsing or asking again.
+func (m *argumentMatcher) answer(i, pos int, args []*Argument) {
+	// Everything from pos on moved.
+	for key := range m.parsed {
+		if key[1] >= pos || key[1]+key[2] > pos {
+			delete(m.parsed, key)
+		}
+	}
+	m.failed = make(map[[2]int]bool)
+	m.failure = nil
+
+	if m.tags[i].Rest {
+		for k, arg := range args {
+			m.parsed[[3]int{i, pos + k, 1}] = parsedArgument{arg: arg}
+		}
+	} else {
+		m.parsed[[3]int{i, pos, 1}] = parsedArgument{arg: args[0]}
+	}
+}
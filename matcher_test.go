@@ -0,0 +1,109 @@
+package sapphire
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newMatcherBot(t *testing.T) *Bot {
+	bot := newTestBot(t)
+	bot.RegisterArgumentType("item", func(ctx *CommandContext, tag *UsageTag, raw string) (interface{}, error) {
+		if !strings.HasPrefix(raw, "item:") {
+			return nil, errors.New("not an item")
+		}
+		return strings.TrimPrefix(raw, "item:"), nil
+	})
+	return bot
+}
+
+// Parses content for usage and returns the values of the arguments, or the error it replied with.
+func matchArgs(t *testing.T, bot *Bot, usage, content string) ([]interface{}, error) {
+	cmd := NewCommand("test", "General", func(ctx *CommandContext) {}).SetUsage(usage)
+	bot.AddCommand(cmd)
+	ctx := &CommandContext{Bot: bot, Command: cmd, Locale: English, RawArgs: Tokenize(content)}
+	m := newArgumentMatcher(ctx)
+	args, ok := m.match(0, 0)
+	if !ok {
+		if m.failure.err == nil {
+			return nil, errors.New("missing " + cmd.Usage[m.failure.tag].Name)
+		}
+		return nil, m.failure.err
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value()
+	}
+	return values, nil
+}
+
+func TestArgumentMatcher(t *testing.T) {
+	bot := newMatcherBot(t)
+	cases := []struct {
+		usage, content, expected string
+	}{
+		// Optionals are skipped when they don't parse.
+		{"[thing:item] <reason:string...>", "hello world", "[<nil> hello world]"},
+		{"[thing:item] <reason:string...>", "item:sword hello", "[sword hello]"},
+		{"[days:int] [reason:string]", "abc", "[<nil> abc]"},
+		// Or when the rest can't match without skipping them.
+		{"[count:int] <a:int> <b:string>", "5 x", "[<nil> 5 x]"},
+		{"[count:int] <a:int> <b:string>", "5 6 x", "[5 6 x]"},
+		// Defaults are used for skipped optionals.
+		{"[days:int=7] <reason:string...>", "spam", "[7 spam]"},
+		// Unions try their types in order.
+		{"<thing:item|int>", "item:shield", "[shield]"},
+		{"<thing:item|int>", "42", "[42]"},
+		{"<length:duration|int> [note:string]", "5", "[5 <nil>]"},
+		// Rest strings are limited by their whole length.
+		{"<text:string{5,}...>", "spamming a lot", "[spamming a lot]"},
+		{"<nums:int{1,10}...>", "1 2 3", "[1 2 3]"},
+	}
+	for _, c := range cases {
+		values, err := matchArgs(t, bot, c.usage, c.content)
+		if got := fmt.Sprint(values); err != nil || got != c.expected {
+			t.Errorf("Matching %q against %q gave %s %v, expected %s", c.content, c.usage, got, err, c.expected)
+		}
+	}
+
+	failures := []struct {
+		usage, content, expected string
+	}{
+		{"[days:int]", "abc", "**days** must be a valid whole number."},
+		{"[thing:item] <reason:string...>", "", "missing reason"},
+		{"<thing:item|int>", "sword", "not an item"},
+		{"<amount:int{1,10}> <note:string>", "50 hi", "**amount** must be between 1 and 10."},
+		{"<text:string{,10}...>", "spamming a lot", "**text** must be at most 10 characters long."},
+		{"<nums:int{1,10}...>", "1 20 3", "**nums** must be between 1 and 10."},
+	}
+	for _, c := range failures {
+		if _, err := matchArgs(t, bot, c.usage, c.content); err == nil || err.Error() != c.expected {
+			t.Errorf("Matching %q against %q gave %v, expected %s", c.content, c.usage, err, c.expected)
+		}
+	}
+}
+
+func TestUnionUsage(t *testing.T) {
+	tags, err := ParseUsage("<target:member|user> <mode:on|off>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags[0].Types) != 2 || tags[0].Choices != nil || len(tags[1].Choices) != 2 || tags[1].Types != nil {
+		t.Errorf("Expected a union and choices but got %+v and %+v", tags[0], tags[1])
+	}
+
+	bot := newMatcherBot(t)
+	cmd := NewCommand("test", "General", func(ctx *CommandContext) {}).SetUsage("<thing:item|int>")
+	bot.AddCommand(cmd)
+	if tag := cmd.Usage[0]; tag.Type != "item|int" || len(tag.Types) != 2 {
+		t.Errorf("Expected the custom type to make a union but got %+v", tag)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected mixing types and choices to panic")
+		}
+	}()
+	bot.AddCommand(NewCommand("mixed", "General", func(ctx *CommandContext) {}).SetUsage("<thing:item|sword>"))
+}
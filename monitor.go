@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"regexp"
+	"sort"
 	"strings"
+	"sync/atomic"
 )
 
 type MonitorHandler func(bot *Bot, ctx *MonitorContext)
@@ -68,12 +70,17 @@ type MonitorContext struct {
 	Bot     *Bot
 }
 
+// Runs every monitor for m in it's own goroutine, unless a collector is waiting for it.
 func monitorHandler(bot *Bot, m *discordgo.Message, edit bool) {
-
 	if m.Author == nil {
 		return // for message edits sometimes author is nil, in practice it works fine when we ignore those.
 	}
 
+	// Someone is waiting for this message, it's an answer not a command.
+	if !edit && !m.Author.Bot && bot.collect(m) {
+		return
+	}
+
 	// Catch panics from monitors.
 	defer func() {
 		if err := recover(); err != nil {
@@ -120,7 +127,7 @@ func monitorHandler(bot *Bot, m *discordgo.Message, edit bool) {
 			continue
 		}
 
-		go monitor.Run(bot, &MonitorContext{
+		mctx := &MonitorContext{
 			Session: bot.Session,
 			Message: m,
 			Author:  m.Author,
@@ -128,10 +135,30 @@ func monitorHandler(bot *Bot, m *discordgo.Message, edit bool) {
 			Monitor: monitor,
 			Guild:   guild,
 			Bot:     bot,
-		})
+		}
+		run := monitor.Run
+		bot.activity.add(1)
+		go func() {
+			defer bot.activity.add(-1)
+			defer func() {
+				if err := recover(); err != nil {
+					bot.ErrorHandler(bot, err)
+				}
+			}()
+			run(bot, mctx)
+		}()
 	}
 }
 
+// HandleMessage runs the monitors (which includes commands) for m as if it was received from discord and waits for them to finish.
+// Monitors waiting for a reply with ctx.AwaitReply count as finished, the message answering them resumes them and is waited for instead.
+// Set edit to true to handle it as an edited message.
+// This is meant for driving the bot without a connection, e.g in tests, see the sapphiretest package.
+func (bot *Bot) HandleMessage(m *discordgo.Message, edit bool) {
+	monitorHandler(bot, m, edit)
+	bot.activity.wait()
+}
+
 func monitorListener(bot *Bot) func(s *discordgo.Session, m *discordgo.MessageCreate) {
 	return func(s *discordgo.Session, m *discordgo.MessageCreate) {
 		monitorHandler(bot, m.Message, false)
@@ -144,10 +171,9 @@ func monitorEditListener(bot *Bot) func(s *discordgo.Session, m *discordgo.Messa
 	}
 }
 
-// The regexp used to parse command flags.
-// Taken from Klasa https://github.com/dirigeants/klasa
-var flagsRegex = regexp.MustCompile("(?:--|—)(\\w[\\w-]+)(?:=(?:[\"]((?:[^\"\\\\]|\\\\.)*)[\"]|[']((?:[^'\\\\]|\\\\.)*)[']|[“”]((?:[^“”\\\\]|\\\\.)*)[“”]|[‘’]((?:[^‘’\\\\]|\\\\.)*)[‘’]|([\\w-]+)))?")
-var delim = regexp.MustCompile("(\\s)(?:\\s)+")
+// The regexp used to parse command flags, it matches a whole token since the tokenizer already removed the quotes of the value.
+// Based on Klasa's https://github.com/dirigeants/klasa
+var flagsRegex = regexp.MustCompile("^(?:--|—)(\\w[\\w-]+)(?:=(.*))?$")
 
 // This is the builtin monitor responsible for running commands.
 func CommandHandlerMonitor(bot *Bot, ctx *MonitorContext) {
@@ -174,61 +200,108 @@ func CommandHandlerMonitor(bot *Bot, ctx *MonitorContext) {
 		}
 	}
 
-	// Parsing flags
-	// It fills the flags maps and strips them out of the original content.
-	flags := make(map[string]string)
-	content := strings.Trim(delim.ReplaceAllString(flagsRegex.ReplaceAllStringFunc(ctx.Message.Content, func(m string) string {
-		sub := flagsRegex.FindStringSubmatch(m)
-		for _, elem := range sub[2:] {
-			if elem != "" {
-				flags[sub[1]] = elem
-				break
-			} else {
-				flags[sub[1]] = sub[1]
-			}
-		}
-		return ""
-	}), "$1"), " ")
+	// Tokenize the content keeping the offsets so the original spacing can still be recovered.
+	content := ctx.Message.Content[len(prefix):]
+	tokens := tokenize(content)
 
-	split := strings.Split(content[len(prefix):], " ")
-
-	if len(split) < 1 {
+	if len(tokens) < 1 {
 		return
 	}
 
-	input := strings.ToLower(split[0])
-	var args []string
-
-	if len(split) > 1 {
-		args = split[1:]
-	}
+	input := strings.ToLower(tokens[0].value)
+	after := content[tokens[0].end:]
+	tokens = tokens[1:]
 
 	cmd := bot.GetCommand(input)
 	if cmd == nil {
+		bot.suggestCommand(ctx, prefix, input, after)
 		return
 	}
 
+	// Walk down to the deepest matching subcommand, e.g !tag create <name> runs create with the arguments after it.
+	for len(tokens) > 0 {
+		sub := cmd.GetSubcommand(strings.ToLower(tokens[0].value))
+		if sub == nil {
+			break
+		}
+		cmd = sub
+		input += " " + strings.ToLower(tokens[0].value)
+		tokens = tokens[1:]
+	}
+
+	// Parsing flags
+	// It fills the flags maps and strips them out of the arguments.
+	// Commands that declare flags only take theirs, otherwise every flag is taken.
+	content = ""
+	if len(tokens) > 0 {
+		content = ctx.Message.Content[len(prefix)+tokens[0].start:]
+	}
+	var flags map[string]string
+	var unknown []string
+	if len(cmd.Flags) > 0 {
+		content, flags, unknown = cmd.splitFlags(content, tokenize(content), bot.UnknownFlags == UnknownFlagsKeep)
+	} else {
+		content, flags = parseFlags(content)
+	}
+	tokens = tokenize(content)
+	args := make([]string, len(tokens))
+	for i, tok := range tokens {
+		args[i] = tok.value
+	}
+
 	// Start constructing a context early so we can call reply and apply the editing rules.
 	// Thanks to monitors most of our fields are filled in our monitor context already so we just redirect them.
 	cctx := &CommandContext{
-		Bot:         bot,
-		Command:     cmd,
-		Message:     ctx.Message,
-		Channel:     ctx.Channel,
-		Session:     ctx.Session,
-		Author:      ctx.Author,
-		RawArgs:     args,
-		Prefix:      prefix,
-		Guild:       ctx.Guild,
-		Flags:       flags,
-		InvokedName: input,
+		Bot:          bot,
+		Command:      cmd,
+		Message:      ctx.Message,
+		Channel:      ctx.Channel,
+		Session:      ctx.Session,
+		Author:       ctx.Author,
+		RawArgs:      args,
+		Prefix:       prefix,
+		Guild:        ctx.Guild,
+		Flags:        flags,
+		InvokedName:  input,
+		argContent:   content,
+		argTokens:    tokens,
+		unknownFlags: unknown,
 	}
 
-	lang := bot.Language(bot, ctx.Message, ctx.Channel.Type == discordgo.ChannelTypeDM)
-	locale, ok := bot.Languages[lang]
+	bot.runCommand(cctx)
+}
+
+// Fills a map with the flags in the content and strips them out of it along with the whitespace before them.
+// Flags are found in the tokens of content so a quoted flag is text.
+func parseFlags(content string) (string, map[string]string) {
+	flags := make(map[string]string)
+	var cut []token
+	for _, tok := range tokenize(content) {
+		if tok.quoted {
+			continue
+		}
+		match := flagsRegex.FindStringSubmatch(tok.value)
+		if match == nil {
+			continue
+		}
+		flags[match[1]] = match[1]
+		if match[2] != "" {
+			flags[match[1]] = match[2]
+		}
+		cut = append(cut, tok)
+	}
+	return cutTokens(content, cut), flags
+}
+
+// Runs the command of a constructed context, resolving the locale, running the inhibitors and parsing the arguments first.
+// This is shared between commands invoked by messages and application commands.
+func (bot *Bot) runCommand(cctx *CommandContext) {
+	cmd := cctx.Command
+	lang := bot.Language(bot, cctx.Message, cctx.Channel.Type == discordgo.ChannelTypeDM)
+	locale := bot.findLanguage(lang)
 
 	// Shouldn't happen unless the user made a mistake returning an invalid string, let's help them find the problem.
-	if !ok {
+	if locale == nil {
 		fmt.Printf("WARNING: bot.Language handler returned a non-existent language '%s' (command execution aborted)\n", lang)
 		return
 	}
@@ -237,38 +310,34 @@ func CommandHandlerMonitor(bot *Bot, ctx *MonitorContext) {
 	cctx.Locale = locale
 
 	// Validations.
-	if !cmd.Enabled {
-		cctx.ReplyLocale("COMMAND_DISABLED")
+	if !bot.runInhibitors(cctx) {
 		return
 	}
 
-	if cmd.OwnerOnly && ctx.Author.ID != bot.OwnerID {
-		cctx.ReplyLocale("COMMAND_OWNER_ONLY")
-		return
-	}
-
-	if cmd.GuildOnly && ctx.Message.GuildID == "" {
-		cctx.ReplyLocale("COMMAND_GUILD_ONLY")
+	// A command that only groups subcommands, tell the user what they can run.
+	if cmd.Run == nil {
+		names := make([]string, 0, len(cmd.Subcommands))
+		for name := range cmd.Subcommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		cctx.ReplyLocale("COMMAND_SUBCOMMAND_REQUIRED", strings.Join(names, ", "))
 		return
 	}
 
 	// If parse args failed it returns false
 	// We don't need to reply since ParseArgs already reports the appropriate error before returning.
-	if !cctx.ParseArgs() {
+	// Flags are parsed first so a bad flag is reported before prompting for the arguments.
+	if !cctx.ParseFlags() || !cctx.ParseArgs() || !cctx.takeCooldown() {
 		return
 	}
 
-	if bot.CommandTyping {
-		ctx.Session.ChannelTyping(ctx.Message.ChannelID)
+	// Application commands already show that we are thinking.
+	if bot.CommandTyping && cctx.Interaction == nil {
+		cctx.Session.ChannelTyping(cctx.Channel.ID)
 	}
 
-	canRun, after := bot.CheckCooldown(ctx.Author.ID, cmd.Name, cmd.Cooldown)
-	if !canRun {
-		cctx.ReplyLocale("COMMAND_COOLDOWN", after)
-		return
-	}
-
-	bot.CommandsRan++
+	atomic.AddInt64(&bot.CommandsRan, 1)
 
 	defer func() {
 		if err := recover(); err != nil {
@@ -276,5 +345,13 @@ func CommandHandlerMonitor(bot *Bot, ctx *MonitorContext) {
 		}
 	}()
 
+	for _, hook := range bot.preRunHooks {
+		hook(cctx)
+	}
+
 	cmd.Run(cctx)
+
+	for _, hook := range bot.postRunHooks {
+		hook(cctx)
+	}
 }
@@ -17,17 +17,19 @@ const (
 )
 
 type Paginator struct {
-	Running   bool                      // If we are running or not.
-	Session   *discordgo.Session        // The discordgo session.
-	ChannelID string                    // The ID of the channel we are on.
-	Template  func() *Embed             // Base template that is passed to AddPage calls.
-	Pages     []*discordgo.MessageEmbed // Embeds for all pages.
-	index     int                       // Index of current page, Use GetIndex() which aquires the lock.
-	Message   *discordgo.Message        // The sent message to be edited as we go
-	AuthorID  string                    // The user that can control this paginator.
-	StopChan  chan bool                 // Stop paginator by sending to this channel.
-	Timeout   time.Duration             // Duration of when the paginator expires. (default: 5minutes)
-	lock      sync.Mutex
+	Running    bool                         // If we are running or not.
+	Session    *discordgo.Session           // The discordgo session.
+	ChannelID  string                       // The ID of the channel we are on.
+	Template   func() *Embed                // Base template that is passed to AddPage calls.
+	Pages      []*discordgo.MessageEmbed    // Embeds for all pages.
+	index      int                          // Index of current page, Use GetIndex() which aquires the lock.
+	Message    *discordgo.Message           // The sent message to be edited as we go
+	AuthorID   string                       // The user that can control this paginator.
+	StopChan   chan bool                    // Stop paginator by sending to this channel.
+	Timeout    time.Duration                // Duration of when the paginator expires. (default: 5minutes)
+	Footer     string                       // Format of the footer of every page with the page number and the number of pages. (default: Page %d/%d)
+	FooterFunc func(page, pages int) string // Formats the footers instead of Footer if set.
+	lock       sync.Mutex
 }
 
 // NewPaginator creates a new paginator and returns it.
@@ -44,13 +46,19 @@ func NewPaginator(session *discordgo.Session, channel, author string) *Paginator
 		AuthorID:  author,
 		StopChan:  make(chan bool),
 		Timeout:   time.Minute * 5,
+		Footer:    "Page %d/%d",
 		Template:  func() *Embed { return NewEmbed() },
 	}
 }
 
-// NewPaginatorForContext creates a new paginator for this command context
+// NewPaginatorForContext creates a new paginator for this command context, the footer is in the context's locale.
+// Translations of PAGINATOR_FOOTER get the page numbers as Printf arguments or as {page} and {pages}.
 func NewPaginatorForContext(ctx *CommandContext) *Paginator {
-	return NewPaginator(ctx.Session, ctx.Channel.ID, ctx.Author.ID)
+	p := NewPaginator(ctx.Session, ctx.Channel.ID, ctx.Author.ID)
+	p.FooterFunc = func(page, pages int) string {
+		return ctx.Localize("PAGINATOR_FOOTER", page, pages, Params{"page": page, "pages": pages})
+	}
+	return p
 }
 
 // SetTemplate sets the base template.
@@ -120,8 +128,12 @@ func (p *Paginator) getPreviousIndex() int {
 // Called by Run to initialize.
 func (p *Paginator) SetFooter() {
 	for index, embed := range p.Pages {
+		text := fmt.Sprintf(p.Footer, index+1, len(p.Pages))
+		if p.FooterFunc != nil {
+			text = p.FooterFunc(index+1, len(p.Pages))
+		}
 		embed.Footer = &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Page %d/%d", index+1, len(p.Pages)),
+			Text: text,
 		}
 	}
 }
@@ -0,0 +1,28 @@
+package sapphire
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"testing"
+)
+
+func TestPaginatorFooter(t *testing.T) {
+	bot := newTestBot(t)
+	bot.AddLanguage(NewLanguage("de").Set("PAGINATOR_FOOTER", "Seite {page} von {pages}"))
+
+	tests := map[*Language]string{
+		English:             "Page 2/3",
+		bot.Languages["de"]: "Seite 2 von 3",
+	}
+	for locale, expected := range tests {
+		ctx := &CommandContext{Bot: bot, Session: bot.Session, Channel: &discordgo.Channel{ID: "channel"},
+			Author: &discordgo.User{ID: "author"}, Locale: locale}
+		p := NewPaginatorForContext(ctx)
+		for _, page := range []string{"a", "b", "c"} {
+			p.AddPageString(page)
+		}
+		p.SetFooter()
+		if got := p.Pages[1].Footer.Text; got != expected {
+			t.Errorf("Expected the footer in %s to be %q but got %q", locale.Name, expected, got)
+		}
+	}
+}
@@ -1,13 +1,17 @@
 package sapphire
 
 import (
+	"fmt"
 	"github.com/bwmarrin/discordgo"
 )
 
 // Utility to help calculate permissions. Since discordgo is too damn low-level
 
 // Permissions represent permission bits for a discord entity.
-type Permissions int
+type Permissions int64
+
+// Every permission bit, discordgo.PermissionAll doesn't have the newer permissions like Moderate Members.
+const allPermissions Permissions = ^0
 
 // PermissionsForRole returns a permissions instance for a role.
 func PermissionsForRole(role *discordgo.Role) Permissions {
@@ -15,29 +19,155 @@ func PermissionsForRole(role *discordgo.Role) Permissions {
 }
 
 // PermissionsForMember returns a permissions instance for a member.
+// These are the guild-wide permissions, use PermissionsForMemberInChannel to take channel overwrites into account.
 func PermissionsForMember(guild *discordgo.Guild, member *discordgo.Member) Permissions {
 	// Owners have all permissions.
 	if member.User.ID == guild.OwnerID {
-		return Permissions(discordgo.PermissionAll)
+		return allPermissions
 	}
-	bits := 0
-	// Combine all permissions from every role.
-	for _, rID := range member.Roles {
-		var role *discordgo.Role
-		for _, gRole := range guild.Roles {
-			if gRole.ID == rID {
-				role = gRole
+	var bits int64
+	// Combine all permissions from every role, the @everyone role shares the guild's ID and every member has it.
+	for _, role := range guild.Roles {
+		if role.ID == guild.ID || hasRole(member, role.ID) {
+			bits |= role.Permissions
+		}
+	}
+	// Administrators bypass everything.
+	if bits&discordgo.PermissionAdministrator != 0 {
+		return allPermissions
+	}
+	return Permissions(bits)
+}
+
+// PermissionsForMemberInChannel returns a permissions instance for a member in a channel.
+// The channel's permission overwrites are applied in the same order discord does:
+// the @everyone overwrite, then the member's role overwrites combined and finally the member's own overwrite.
+// Threads don't have overwrites, the overwrites of their parent channel are used, it's found in the guild's channels from the state.
+func PermissionsForMemberInChannel(guild *discordgo.Guild, channel *discordgo.Channel, member *discordgo.Member) Permissions {
+	base := PermissionsForMember(guild, member)
+	// Overwrites can't take anything away from owners and administrators.
+	if base == allPermissions {
+		return base
+	}
+	bits := int64(base)
+
+	if channel.IsThread() {
+		for _, parent := range guild.Channels {
+			if parent.ID == channel.ParentID {
+				channel = parent
 				break
 			}
 		}
-		// Do we have a choice if it was nil?
-		if role != nil {
-			bits |= role.Permissions
+	}
+
+	// Overwrite IDs are unique across roles and members so we can tell them apart without looking at the type.
+	var everyone, own *discordgo.PermissionOverwrite
+	var allow, deny int64
+	for _, overwrite := range channel.PermissionOverwrites {
+		switch {
+		case overwrite.ID == guild.ID:
+			everyone = overwrite
+		case overwrite.ID == member.User.ID:
+			own = overwrite
+		case hasRole(member, overwrite.ID):
+			allow |= overwrite.Allow
+			deny |= overwrite.Deny
 		}
 	}
+
+	if everyone != nil {
+		bits &^= everyone.Deny
+		bits |= everyone.Allow
+	}
+
+	bits &^= deny
+	bits |= allow
+
+	if own != nil {
+		bits &^= own.Deny
+		bits |= own.Allow
+	}
+
 	return Permissions(bits)
 }
 
-func (perms Permissions) Has(bits int) bool {
-	return (int(perms) & bits) == bits
+// Checks if member has the role with the given id.
+func hasRole(member *discordgo.Member, id string) bool {
+	for _, rID := range member.Roles {
+		if rID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (perms Permissions) Has(bits int64) bool {
+	return (int64(perms) & bits) == bits
+}
+
+// Human readable names of the permission bits, ordered by their bit.
+var permissionNames = []struct {
+	bit  int64
+	name string
+}{
+	{discordgo.PermissionCreateInstantInvite, "Create Instant Invite"},
+	{discordgo.PermissionKickMembers, "Kick Members"},
+	{discordgo.PermissionBanMembers, "Ban Members"},
+	{discordgo.PermissionAdministrator, "Administrator"},
+	{discordgo.PermissionManageChannels, "Manage Channels"},
+	{discordgo.PermissionManageServer, "Manage Server"},
+	{discordgo.PermissionAddReactions, "Add Reactions"},
+	{discordgo.PermissionViewAuditLogs, "View Audit Log"},
+	{discordgo.PermissionVoicePrioritySpeaker, "Priority Speaker"},
+	{discordgo.PermissionVoiceStreamVideo, "Video"},
+	{discordgo.PermissionViewChannel, "View Channel"},
+	{discordgo.PermissionSendMessages, "Send Messages"},
+	{discordgo.PermissionSendTTSMessages, "Send TTS Messages"},
+	{discordgo.PermissionManageMessages, "Manage Messages"},
+	{discordgo.PermissionEmbedLinks, "Embed Links"},
+	{discordgo.PermissionAttachFiles, "Attach Files"},
+	{discordgo.PermissionReadMessageHistory, "Read Message History"},
+	{discordgo.PermissionMentionEveryone, "Mention Everyone"},
+	{discordgo.PermissionUseExternalEmojis, "Use External Emojis"},
+	{discordgo.PermissionViewGuildInsights, "View Server Insights"},
+	{discordgo.PermissionVoiceConnect, "Connect"},
+	{discordgo.PermissionVoiceSpeak, "Speak"},
+	{discordgo.PermissionVoiceMuteMembers, "Mute Members"},
+	{discordgo.PermissionVoiceDeafenMembers, "Deafen Members"},
+	{discordgo.PermissionVoiceMoveMembers, "Move Members"},
+	{discordgo.PermissionVoiceUseVAD, "Use Voice Activity"},
+	{discordgo.PermissionChangeNickname, "Change Nickname"},
+	{discordgo.PermissionManageNicknames, "Manage Nicknames"},
+	{discordgo.PermissionManageRoles, "Manage Roles"},
+	{discordgo.PermissionManageWebhooks, "Manage Webhooks"},
+	{discordgo.PermissionManageEmojis, "Manage Emojis and Stickers"},
+	{discordgo.PermissionUseSlashCommands, "Use Application Commands"},
+	{discordgo.PermissionVoiceRequestToSpeak, "Request to Speak"},
+	{discordgo.PermissionManageEvents, "Manage Events"},
+	{discordgo.PermissionManageThreads, "Manage Threads"},
+	{discordgo.PermissionCreatePublicThreads, "Create Public Threads"},
+	{discordgo.PermissionCreatePrivateThreads, "Create Private Threads"},
+	{discordgo.PermissionUseExternalStickers, "Use External Stickers"},
+	{discordgo.PermissionSendMessagesInThreads, "Send Messages in Threads"},
+	{discordgo.PermissionUseActivities, "Use Activities"},
+	{discordgo.PermissionModerateMembers, "Timeout Members"},
+}
+
+// Missing returns the names of the permissions in bits that perms doesn't have.
+func (perms Permissions) Missing(bits int64) []string {
+	missing := []string{}
+	var known int64
+	for _, perm := range permissionNames {
+		known |= perm.bit
+		if bits&perm.bit != 0 && !perms.Has(perm.bit) {
+			missing = append(missing, perm.name)
+		}
+	}
+	// Bits newer than this table still need a name to show.
+	for bit := int64(1); bit > 0 && bit <= bits; bit <<= 1 {
+		if bits&bit != 0 && known&bit == 0 && !perms.Has(bit) {
+			missing = append(missing, fmt.Sprintf("Unknown Permission (%d)", bit))
+		}
+	}
+	return missing
 }
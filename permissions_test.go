@@ -0,0 +1,95 @@
+package sapphire
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"testing"
+)
+
+func TestPermissionsForMemberInChannel(t *testing.T) {
+	guild := &discordgo.Guild{
+		ID:      "100",
+		OwnerID: "1",
+		Roles: []*discordgo.Role{
+			{ID: "100", Permissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages},
+			{ID: "200", Permissions: discordgo.PermissionManageMessages},
+			{ID: "300", Permissions: discordgo.PermissionAdministrator},
+		},
+	}
+	channel := &discordgo.Channel{
+		ID: "500",
+		PermissionOverwrites: []*discordgo.PermissionOverwrite{
+			{ID: "100", Deny: discordgo.PermissionSendMessages},
+			{ID: "200", Allow: discordgo.PermissionSendMessages, Deny: discordgo.PermissionManageMessages},
+			{ID: "3", Deny: discordgo.PermissionViewChannel},
+		},
+	}
+
+	owner := &discordgo.Member{User: &discordgo.User{ID: "1"}}
+	everyone := &discordgo.Member{User: &discordgo.User{ID: "2"}}
+	denied := &discordgo.Member{User: &discordgo.User{ID: "3"}, Roles: []string{"200"}}
+	mod := &discordgo.Member{User: &discordgo.User{ID: "4"}, Roles: []string{"200"}}
+	admin := &discordgo.Member{User: &discordgo.User{ID: "5"}, Roles: []string{"300"}}
+
+	if !PermissionsForMember(guild, everyone).Has(discordgo.PermissionSendMessages) {
+		t.Error("Expected the @everyone role to be included in the guild permissions")
+	}
+	if PermissionsForMemberInChannel(guild, channel, everyone).Has(discordgo.PermissionSendMessages) {
+		t.Error("Expected the @everyone overwrite to deny sending messages")
+	}
+
+	perms := PermissionsForMemberInChannel(guild, channel, mod)
+	if !perms.Has(discordgo.PermissionSendMessages) {
+		t.Error("Expected the role overwrite to allow sending messages over the @everyone overwrite")
+	}
+	if perms.Has(discordgo.PermissionManageMessages) {
+		t.Error("Expected the role overwrite to deny managing messages")
+	}
+
+	if PermissionsForMemberInChannel(guild, channel, denied).Has(discordgo.PermissionViewChannel) {
+		t.Error("Expected the member overwrite to deny viewing the channel")
+	}
+
+	for _, member := range []*discordgo.Member{owner, admin} {
+		if !PermissionsForMemberInChannel(guild, channel, member).Has(discordgo.PermissionSendMessages | discordgo.PermissionManageMessages) {
+			t.Errorf("Expected %s to bypass the overwrites", member.User.ID)
+		}
+	}
+
+	if missing := PermissionsForMemberInChannel(guild, channel, everyone).Missing(discordgo.PermissionSendMessages | discordgo.PermissionViewChannel); len(missing) != 1 || missing[0] != "Send Messages" {
+		t.Errorf("Expected only Send Messages to be missing but got %v", missing)
+	}
+
+	// Newer permissions than discordgo.PermissionAll must still be checked.
+	if PermissionsForMemberInChannel(guild, channel, everyone).Has(discordgo.PermissionModerateMembers) {
+		t.Error("Expected @everyone to not have Moderate Members")
+	}
+	if missing := Permissions(0).Missing(discordgo.PermissionModerateMembers | 1<<50); len(missing) != 2 || missing[0] != "Timeout Members" {
+		t.Errorf("Expected Timeout Members and an unknown permission to be missing but got %v", missing)
+	}
+	if !PermissionsForMemberInChannel(guild, channel, admin).Has(discordgo.PermissionModerateMembers | discordgo.PermissionManageThreads) {
+		t.Error("Expected administrators to have the newer permissions")
+	}
+}
+
+func TestPermissionsInThread(t *testing.T) {
+	guild := &discordgo.Guild{
+		ID:    "100",
+		Roles: []*discordgo.Role{{ID: "100", Permissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessagesInThreads}},
+	}
+	parent := &discordgo.Channel{
+		ID:                   "500",
+		Type:                 discordgo.ChannelTypeGuildText,
+		PermissionOverwrites: []*discordgo.PermissionOverwrite{{ID: "100", Deny: discordgo.PermissionSendMessagesInThreads}},
+	}
+	thread := &discordgo.Channel{ID: "600", ParentID: "500", Type: discordgo.ChannelTypeGuildPublicThread}
+	guild.Channels = []*discordgo.Channel{parent, thread}
+	member := &discordgo.Member{User: &discordgo.User{ID: "2"}}
+
+	perms := PermissionsForMemberInChannel(guild, thread, member)
+	if perms.Has(discordgo.PermissionSendMessagesInThreads) {
+		t.Error("Expected the overwrites of the parent channel to apply to the thread")
+	}
+	if !perms.Has(discordgo.PermissionViewChannel) {
+		t.Error("Expected the thread to keep the guild permissions")
+	}
+}
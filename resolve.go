@@ -0,0 +1,245 @@
+package sapphire
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Resolving members, users, roles and channels by their names when they aren't mentioned.
+
+// The most matches the "which one did you mean?" prompt lists, more than that and the user has to be more specific.
+const maxPromptChoices = 10
+
+// Something that can be found by name.
+type nameMatch struct {
+	names   []string    // The names to match against, e.g the username and nickname.
+	display string      // How it's shown in the prompt.
+	value   interface{} // The value the argument gets.
+}
+
+// Returns the items matching query ignoring case, exact matches are preferred over prefix matches, which are preferred
+// over matches anywhere in the name, which are preferred over names with a small typo.
+func matchNames(query string, items []*nameMatch) []*nameMatch {
+	query = strings.ToLower(query)
+	if query == "" {
+		return nil
+	}
+	// A typo of one character for every four is close enough.
+	typos := len([]rune(query)) / 4
+
+	var tiers [4][]*nameMatch
+	for _, item := range items {
+		best := len(tiers)
+		for _, name := range item.names {
+			name = strings.ToLower(name)
+			tier := len(tiers)
+			switch {
+			case name == "":
+			case name == query:
+				tier = 0
+			case strings.HasPrefix(name, query):
+				tier = 1
+			case strings.Contains(name, query):
+				tier = 2
+			case typos > 0 && levenshtein(name, query) <= typos:
+				tier = 3
+			}
+			if tier < best {
+				best = tier
+			}
+		}
+		if best < len(tiers) {
+			tiers[best] = append(tiers[best], item)
+		}
+	}
+
+	for _, tier := range tiers {
+		if len(tier) > 0 {
+			sort.SliceStable(tier, func(i, j int) bool {
+				return tier[i].display < tier[j].display
+			})
+			return tier
+		}
+	}
+	return nil
+}
+
+// A name matching more than one item, it fails the argument like any error so optionals can be skipped without asking.
+// The author is only asked which one they meant by chooseName once nothing else could take the argument.
+type ambiguousName struct {
+	message string
+	matches []*nameMatch
+}
+
+func (err *ambiguousName) Error() string {
+	return err.message
+}
+
+// Resolves query against items for the argument described by tag, returns nil if nothing matches.
+// If multiple items match it fails with an ambiguousName.
+func (ctx *CommandContext) resolveName(tag *UsageTag, query string, items []*nameMatch) (interface{}, error) {
+	matches := matchNames(query, items)
+	switch {
+	case len(matches) == 0:
+		return nil, nil
+	case len(matches) == 1:
+		return matches[0].value, nil
+	case len(matches) > maxPromptChoices:
+		return nil, errors.New(ctx.Localize("ARGUMENT_TOO_MANY_MATCHES", tag.Name))
+	}
+	return nil, &ambiguousName{message: ctx.Localize("ARGUMENT_TOO_MANY_MATCHES", tag.Name), matches: matches}
+}
+
+// Asks the author which of the ambiguous matches they meant for the argument described by tag.
+func (ctx *CommandContext) chooseName(tag *UsageTag, ambiguous *ambiguousName) (interface{}, error) {
+	var list strings.Builder
+	for i, match := range ambiguous.matches {
+		fmt.Fprintf(&list, "\n%d. %s", i+1, Escape(match.display))
+	}
+	ctx.Reply("%s", ctx.Localize("ARGUMENT_WHICH_ONE", tag.Name, list.String()))
+
+	answer := ctx.AwaitReply(ctx.Bot.PromptTimeout)
+	if answer == nil {
+		return nil, &promptAbort{ctx.Localize("PROMPT_TIMEOUT")}
+	}
+	content := strings.TrimSpace(answer.Content)
+	if strings.EqualFold(content, ctx.Localize("PROMPT_CANCEL_KEYWORD")) {
+		return nil, &promptAbort{ctx.Localize("PROMPT_CANCELLED")}
+	}
+	n, err := strconv.Atoi(content)
+	if err != nil || n < 1 || n > len(ambiguous.matches) {
+		return nil, errors.New(ctx.Localize("ARGUMENT_INVALID_CHOICE"))
+	}
+	return ambiguous.matches[n-1].value, nil
+}
+
+// The name and the name with the discriminator of a user, the discriminator is 0 for users with the new usernames.
+func userNames(user *discordgo.User) []string {
+	names := []string{user.Username}
+	if user.Discriminator != "" && user.Discriminator != "0" {
+		names = append(names, user.Username+"#"+user.Discriminator)
+	}
+	return names
+}
+
+func memberMatch(member *discordgo.Member) *nameMatch {
+	display := member.User.String()
+	if member.Nick != "" {
+		display = member.Nick + " (" + display + ")"
+	}
+	return &nameMatch{names: append(userNames(member.User), member.Nick), display: display, value: member}
+}
+
+// Finds a member of the current guild by username#discriminator, username or nickname.
+func (ctx *CommandContext) findMember(tag *UsageTag, query string) (*discordgo.Member, error) {
+	if ctx.Guild == nil {
+		return nil, nil
+	}
+	query = strings.TrimPrefix(query, "@")
+
+	ctx.Session.State.RLock()
+	items := make([]*nameMatch, 0, len(ctx.Guild.Members))
+	for _, member := range ctx.Guild.Members {
+		items = append(items, memberMatch(member))
+	}
+	ctx.Session.State.RUnlock()
+
+	value, err := ctx.resolveName(tag, query, items)
+	if value == nil {
+		return nil, err
+	}
+	return value.(*discordgo.Member), nil
+}
+
+// Finds a user by name from the members of the current guild, or in DMs from the guilds the author is in.
+// Other guilds are never searched so their members can't be listed by strangers.
+func (ctx *CommandContext) findUser(tag *UsageTag, query string) (*discordgo.User, error) {
+	query = strings.TrimPrefix(query, "@")
+
+	ctx.Session.State.RLock()
+	guilds := []*discordgo.Guild{ctx.Guild}
+	if ctx.Guild == nil {
+		guilds = nil
+		for _, guild := range ctx.Session.State.Guilds {
+			if hasMember(guild, ctx.Author.ID) {
+				guilds = append(guilds, guild)
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	var items []*nameMatch
+	for _, guild := range guilds {
+		for _, member := range guild.Members {
+			if seen[member.User.ID] {
+				continue
+			}
+			seen[member.User.ID] = true
+			items = append(items, &nameMatch{names: userNames(member.User), display: member.User.String(), value: member.User})
+		}
+	}
+	ctx.Session.State.RUnlock()
+
+	value, err := ctx.resolveName(tag, query, items)
+	if value == nil {
+		return nil, err
+	}
+	return value.(*discordgo.User), nil
+}
+
+// Checks if the user is a member of guild, the state must be locked.
+func hasMember(guild *discordgo.Guild, userID string) bool {
+	for _, member := range guild.Members {
+		if member.User.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Finds a channel of the current guild by name, categories and voice channels included.
+func (ctx *CommandContext) findChannel(tag *UsageTag, query string) (*discordgo.Channel, error) {
+	if ctx.Guild == nil {
+		return nil, nil
+	}
+	query = strings.TrimPrefix(query, "#")
+
+	ctx.Session.State.RLock()
+	items := make([]*nameMatch, 0, len(ctx.Guild.Channels))
+	for _, channel := range ctx.Guild.Channels {
+		items = append(items, &nameMatch{names: []string{channel.Name}, display: "#" + channel.Name, value: channel})
+	}
+	ctx.Session.State.RUnlock()
+
+	value, err := ctx.resolveName(tag, query, items)
+	if value == nil {
+		return nil, err
+	}
+	return value.(*discordgo.Channel), nil
+}
+
+// Finds a role of the current guild by name.
+func (ctx *CommandContext) findRole(tag *UsageTag, query string) (*discordgo.Role, error) {
+	if ctx.Guild == nil {
+		return nil, nil
+	}
+	if query != "@everyone" {
+		query = strings.TrimPrefix(query, "@")
+	}
+
+	ctx.Session.State.RLock()
+	items := make([]*nameMatch, 0, len(ctx.Guild.Roles))
+	for _, role := range ctx.Guild.Roles {
+		items = append(items, &nameMatch{names: []string{role.Name}, display: "@" + strings.TrimPrefix(role.Name, "@"), value: role})
+	}
+	ctx.Session.State.RUnlock()
+
+	value, err := ctx.resolveName(tag, query, items)
+	if value == nil {
+		return nil, err
+	}
+	return value.(*discordgo.Role), nil
+}
@@ -8,7 +8,10 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -29,22 +32,38 @@ type Bot struct {
 	Prefix           PrefixHandler       // The handler called to get the prefix. (default: !)
 	Language         LocaleHandler       // The handler called to get the language (default: en-US)
 	Commands         map[string]*Command // Map of commands.
-	CommandsRan      int                 // Commands ran.
+	CommandsRan      int64               // Commands ran, use sync/atomic to read it.
 	Monitors         map[string]*Monitor // Map of monitors.
 	aliases          map[string]string
-	CommandCooldowns map[string]map[string]time.Time
-	CommandEdits     map[string]string
+	CommandCooldowns CooldownStore        // Store for command cooldowns. (default: in memory)
+	CommandEdits     EditStore            // Store for editable command responses. (default: in memory)
+	EditWindow       time.Duration        // How long a command can be edited to edit it's response. (default: 1 hour)
 	OwnerID          string               // Bot owner's ID (default: fetched from application info)
 	InvitePerms      int                  // Permissions bits to use for the invite link. (default: 3072)
 	Languages        map[string]*Language // Map of languages.
-	DefaultLocale    *Language            // Default locale to fallback. (default: en-US)
+	DefaultLocale    *Language            // Default locale to fallback, tried before DefaultLocales if it isn't the first of them. (default: en-US)
+	DefaultLocales   []string             // The locales to fallback to in order after the current locale's chain. (default: en-US)
+	LocaleFallbacks  map[string][]string  // The fallbacks of locales, see SetLocaleFallbacks.
 	CommandTyping    bool                 // Wether to start typing when a command is being ran. (default: true)
 	ErrorHandler     ErrorHandler         // The handler to catch panics in monitors (which includes commands).
 	MentionPrefix    bool                 // Wether to allow @mention of the bot to be used as a prefix too. (default: true)
 	sweepTicker      *time.Ticker
-	Application      *discordgo.Application // The bot's application.
-	Uptime           time.Time              // The time the bot hit ready event.
-	Color            int                    // The color used in builtin commands's embeds.
+	sweepDone        chan struct{}             // Closed to stop sweeping.
+	Application      *discordgo.Application    // The bot's application.
+	Uptime           time.Time                 // The time the bot hit ready event.
+	Color            int                       // The color used in builtin commands's embeds.
+	ArgumentTypes    map[string]ArgumentParser // Map of argument types usable in usage strings.
+	Inhibitors       []*Inhibitor              // The inhibitors that run before every command, in order.
+	PromptTimeout    time.Duration             // How long to wait for answers to prompts, e.g "which one did you mean?" (default: 30 seconds)
+	UnknownFlags     UnknownFlagMode           // What happens to flags a command didn't declare, only for commands that declare flags. (default: UnknownFlagsError)
+	Suggestions      SuggestionMode            // What happens when someone runs a command that doesn't exist. (default: SuggestionsOff)
+	SuggestCooldown  time.Duration             // How often commands can be suggested in a channel. (default: 10 seconds)
+	preRunHooks      []CommandHook
+	suggestLimits    *MemoryCooldownStore // When suggestions were sent in each channel, kept apart from the command cooldowns.
+	postRunHooks     []CommandHook
+	collectors       []*collector
+	collectorsLock   sync.Mutex
+	activity         *activity
 }
 
 // New creates a new sapphire bot, pass in a discordgo instance configured with your token.
@@ -62,34 +81,44 @@ func New(s *discordgo.Session) *Bot {
 		},
 		Commands:         make(map[string]*Command),
 		aliases:          make(map[string]string),
+		activity:         newActivity(),
+		PromptTimeout:    30 * time.Second,
+		SuggestCooldown:  10 * time.Second,
 		Languages:        make(map[string]*Language),
+		LocaleFallbacks:  make(map[string][]string),
 		CommandsRan:      0,
 		InvitePerms:      3072,
-		CommandCooldowns: make(map[string]map[string]time.Time),
-		CommandEdits:     make(map[string]string),
+		CommandCooldowns: NewMemoryCooldownStore(),
+		CommandEdits:     NewMemoryEditStore(),
+		suggestLimits:    NewMemoryCooldownStore(),
 		Monitors:         make(map[string]*Monitor),
 		CommandTyping:    true,
-		sweepTicker:      time.NewTicker(1 * time.Hour),
+		EditWindow:       time.Hour,
+		sweepTicker:      time.NewTicker(time.Minute),
+		sweepDone:        make(chan struct{}),
 		Application:      nil,
 		MentionPrefix:    true,
 		Color:            COLOR,
+		ArgumentTypes:    builtinArgumentTypes(),
 	}
 	bot.AddLanguage(English)
 	bot.SetDefaultLocale("en-US")
 	bot.AddMonitor(NewMonitor("commandHandler", CommandHandlerMonitor).AllowEdits())
+	bot.AddInhibitor("disabled", DisabledInhibitor).
+		AddInhibitor("ownerOnly", OwnerOnlyInhibitor).
+		AddInhibitor("guildOnly", GuildOnlyInhibitor).
+		AddInhibitor("permissions", PermissionsInhibitor).
+		AddInhibitor("cooldown", CooldownInhibitor)
 	s.AddHandler(monitorListener(bot))
 	s.AddHandler(monitorEditListener(bot))
+	s.AddHandler(interactionListener(bot))
+	s.AddHandler(reactionListener(bot))
 	s.AddHandlerOnce(func(s *discordgo.Session, ready *discordgo.Ready) {
 		bot.Uptime = time.Now()
 
-		// Sweeps all cooldowns/edits every hour to prevent infinite memory usage
-		// While even active cooldowns gets reset it is fine though, as its only hourly
-		// and is not too common for users to even notice it, same for edits.
-		go func() {
-			<-bot.sweepTicker.C
-			bot.CommandCooldowns = make(map[string]map[string]time.Time)
-			bot.CommandEdits = make(map[string]string)
-		}()
+		// Evicts the expired cooldowns/edits every minute to prevent infinite memory usage
+		// Active ones are kept so users don't notice anything.
+		go bot.sweepLoop()
 
 		// TODO: for some reason it says bots cannot use this endpoint, i've seen a similar usecase before
 		// try to figure out a way.
@@ -117,6 +146,49 @@ func (bot *Bot) SetInvitePerms(bits int) *Bot {
 	return bot
 }
 
+// SetCooldownStore sets the store used to keep track of command cooldowns.
+func (bot *Bot) SetCooldownStore(store CooldownStore) *Bot {
+	bot.CommandCooldowns = store
+	return bot
+}
+
+// SetEditStore sets the store used to keep track of command responses for editing.
+func (bot *Bot) SetEditStore(store EditStore) *Bot {
+	bot.CommandEdits = store
+	return bot
+}
+
+// SetEditWindow sets how long after running a command editing it edits the response, after that a new response is sent.
+func (bot *Bot) SetEditWindow(window time.Duration) *Bot {
+	bot.EditWindow = window
+	return bot
+}
+
+// SetUnknownFlags sets what happens to flags a command didn't declare, either they are an error or they are left in the arguments.
+// Commands that don't declare any flags take every flag.
+func (bot *Bot) SetUnknownFlags(mode UnknownFlagMode) *Bot {
+	bot.UnknownFlags = mode
+	return bot
+}
+
+// SetSuggestions sets what happens when someone runs a command that doesn't exist, see SuggestionMode.
+func (bot *Bot) SetSuggestions(mode SuggestionMode) *Bot {
+	bot.Suggestions = mode
+	return bot
+}
+
+// SetSuggestCooldown sets how often commands can be suggested in a channel, 0 suggests every time.
+func (bot *Bot) SetSuggestCooldown(cooldown time.Duration) *Bot {
+	bot.SuggestCooldown = cooldown
+	return bot
+}
+
+// SetPromptTimeout sets how long to wait for the author to answer prompts before giving up.
+func (bot *Bot) SetPromptTimeout(timeout time.Duration) *Bot {
+	bot.PromptTimeout = timeout
+	return bot
+}
+
 // SetErrorHandler sets the function to handle panics that happens in monitors (which includes commands)
 func (bot *Bot) SetErrorHandler(fn ErrorHandler) *Bot {
 	bot.ErrorHandler = fn
@@ -124,13 +196,17 @@ func (bot *Bot) SetErrorHandler(fn ErrorHandler) *Bot {
 }
 
 // Sets the default locale to fallback when the bot can't find a key in the current locale.
-// Panics if locale isn't registered.
-func (bot *Bot) SetDefaultLocale(locale string) *Bot {
-	if lang, ok := bot.Languages[locale]; !ok {
-		panic(fmt.Sprintf("The language '%s' cannot be found.", locale))
-	} else {
-		bot.DefaultLocale = lang
+// More locales can be given to try in order, e.g SetDefaultLocale("es", "en-US")
+// Panics if a locale isn't registered.
+func (bot *Bot) SetDefaultLocale(locale string, fallbacks ...string) *Bot {
+	locales := append([]string{locale}, fallbacks...)
+	for _, name := range locales {
+		if bot.GetLanguage(name) == nil {
+			panic(fmt.Sprintf("The language '%s' cannot be found.", name))
+		}
 	}
+	bot.DefaultLocale = bot.GetLanguage(locale)
+	bot.DefaultLocales = locales
 	return bot
 }
 
@@ -163,10 +239,46 @@ func (bot *Bot) Wait() {
 	<-sc
 	// Cleanly close down the Discord session.
 	bot.Session.Close()
+	bot.stopSweeping()
+}
+
+// Sweeps the stores every tick until stopSweeping is called.
+func (bot *Bot) sweepLoop() {
+	for {
+		select {
+		case <-bot.sweepTicker.C:
+			bot.sweep()
+		case <-bot.sweepDone:
+			return
+		}
+	}
+}
+
+// Stops the ticker and the sweep loop, stopping the ticker doesn't close it's channel so the loop is told to return.
+func (bot *Bot) stopSweeping() {
 	bot.sweepTicker.Stop()
+	close(bot.sweepDone)
 }
 
+// Evicts the expired cooldowns and edits, the active ones are kept.
+func (bot *Bot) sweep() {
+	bot.CommandCooldowns.Sweep()
+	bot.CommandEdits.Sweep()
+	bot.suggestLimits.Sweep()
+}
+
+// RegisterArgumentType registers a new argument type that can be used in usage strings, e.g <item:inventoryItem>
+// Registering an existing type overrides it, builtins included.
+// Types must be registered before adding the commands that use them.
+func (bot *Bot) RegisterArgumentType(name string, parser ArgumentParser) *Bot {
+	bot.ArgumentTypes[name] = parser
+	return bot
+}
+
+// AddCommand registers a command.
+// Panics if the usage string of the command or any of it's subcommands refers to an argument type that isn't registered.
 func (bot *Bot) AddCommand(cmd *Command) *Bot {
+	bot.validateUsage(cmd)
 	c, ok := bot.Commands[cmd.Name]
 	// If we are overriding an existing command ensure we unload any state it loaded in the bot, mainly the aliases.
 	if ok {
@@ -181,6 +293,59 @@ func (bot *Bot) AddCommand(cmd *Command) *Bot {
 	return bot
 }
 
+// Panics if cmd or any of it's subcommands uses an unknown argument type in it's usage or flags.
+// Flags can't share a name with an argument either, both are options of the application command.
+func (bot *Bot) validateUsage(cmd *Command) {
+	names := make(map[string]bool)
+	for _, tag := range cmd.Usage {
+		if err := bot.validateTag(tag); err != "" {
+			panic(fmt.Sprintf("The command '%s' %s for the argument '%s'.", cmd.FullName(), err, tag.Name))
+		}
+		names[applicationOptionName(tag)] = true
+	}
+	for _, flag := range cmd.Flags {
+		if err := bot.validateTag(flag.tag); err != "" {
+			panic(fmt.Sprintf("The command '%s' %s for the flag '%s'.", cmd.FullName(), err, flag.Name))
+		}
+		if names[applicationOptionName(flag.tag)] {
+			panic(fmt.Sprintf("The command '%s' has a flag and an argument both called '%s'.", cmd.FullName(), flag.Name))
+		}
+	}
+	for _, sub := range cmd.Subcommands {
+		bot.validateUsage(sub)
+	}
+}
+
+// Returns what's wrong with the tag's type or an empty string if it's valid.
+// Choices that are all registered types are turned into unions and choices that mix types and values are invalid.
+func (bot *Bot) validateTag(tag *UsageTag) string {
+	if tag.Type == "string" && len(tag.Choices) > 0 {
+		types := 0
+		for _, choice := range tag.Choices {
+			if _, ok := bot.ArgumentTypes[choice]; ok {
+				types++
+			}
+		}
+		if types == len(tag.Choices) {
+			tag.Types = tag.Choices
+			tag.Type = strings.Join(tag.Choices, "|")
+			tag.Choices = nil
+		} else if types > 0 {
+			return "mixes types and choices"
+		}
+	}
+	types := tag.Types
+	if len(types) == 0 {
+		types = []string{tag.Type}
+	}
+	for _, typ := range types {
+		if _, ok := bot.ArgumentTypes[typ]; !ok {
+			return fmt.Sprintf("uses the unknown argument type '%s'", typ)
+		}
+	}
+	return ""
+}
+
 // GetCommand returns a command by name, it also searches by aliases, returns nil if not found.
 func (bot *Bot) GetCommand(name string) *Command {
 	cmd, ok := bot.Commands[name]
@@ -209,6 +374,10 @@ func (bot *Bot) MustConnect() {
 // AddLanguage adds the specified language.
 func (bot *Bot) AddLanguage(lang *Language) *Bot {
 	bot.Languages[lang.Name] = lang
+	// Replacing the default language, e.g a merged en-US.
+	if bot.DefaultLocale != nil && bot.DefaultLocale.Name == lang.Name {
+		bot.DefaultLocale = lang
+	}
 	return bot
 }
 
@@ -222,32 +391,45 @@ func (bot *Bot) AddMonitor(m *Monitor) *Bot {
 // The second value is if user can't run then it will be the amount of seconds
 // to wait before being able to.
 // Note this function assumes the user will run the command and will place the user on cooldown if it isn't already.
+// This shares the cooldowns of commands using the CooldownUser scope with a single use.
 func (bot *Bot) CheckCooldown(userID, command string, cooldownSec int) (bool, int) {
 	if cooldownSec == 0 {
 		return true, 0
 	}
 
-	cooldown := time.Duration(cooldownSec) * time.Second
-	user, ok := bot.CommandCooldowns[userID]
+	ok, after := bot.CommandCooldowns.Check("user:"+userID+":"+command, 1, time.Duration(cooldownSec)*time.Second)
+	return ok, int(after.Seconds())
+}
 
-	if !ok {
-		bot.CommandCooldowns[userID] = make(map[string]time.Time)
-		user = bot.CommandCooldowns[userID]
+// Renders the subcommands of cmd as an indented tree for the help command.
+func subcommandTree(prefix string, cmd *Command, depth int) string {
+	names := make([]string, 0, len(cmd.Subcommands))
+	for name := range cmd.Subcommands {
+		names = append(names, name)
 	}
-
-	last, ok := user[command]
-
-	if !ok {
-		user[command] = time.Now()
-		return true, 0
+	sort.Strings(names)
+
+	var tree string
+	for _, name := range names {
+		sub := cmd.Subcommands[name]
+		usage := strings.TrimSpace(fmt.Sprintf("%s%s %s", prefix, sub.FullName(), HumanizeUsage(sub.UsageString)))
+		tree += fmt.Sprintf("%s`%s` - %s\n", strings.Repeat("\u2003", depth), usage, sub.Description)
+		tree += subcommandTree(prefix, sub, depth+1)
 	}
+	return tree
+}
 
-	if !time.Now().After(last.Add(cooldown)) {
-		return false, int(time.Until(last.Add(cooldown)).Seconds())
+// Renders the declared flags of cmd for the help command.
+func flagList(ctx *CommandContext, cmd *Command) string {
+	var list string
+	for _, flag := range cmd.Flags {
+		line := fmt.Sprintf("`%s` - %s", flag.usage(), flag.Description)
+		if flag.Default != "" {
+			line += " " + ctx.Localize("COMMAND_HELP_DEFAULT", flag.Default)
+		}
+		list += line + "\n"
 	}
-
-	user[command] = time.Now()
-	return true, 0
+	return list
 }
 
 // LoadBuiltins loads the default set of builtin command, they are:
@@ -262,38 +444,52 @@ func (bot *Bot) LoadBuiltins() *Bot {
 		if err != nil {
 			return
 		}
-		usertime, err := ctx.Message.Timestamp.Parse()
-		if err != nil {
-			return
-		}
-		bottime, err := msg.Timestamp.Parse()
-		if err != nil {
-			return
-		}
-		ctx.EditLocale(msg, "COMMAND_PING_PONG", bottime.Sub(usertime).Milliseconds(), ctx.Session.HeartbeatLatency().Milliseconds())
+		ctx.EditLocale(msg, "COMMAND_PING_PONG", msg.Timestamp.Sub(ctx.Message.Timestamp).Milliseconds(), ctx.Session.HeartbeatLatency().Milliseconds())
 	}).SetDescription("Pong! Responds with Bot latency."))
 
 	bot.AddCommand(NewCommand("help", "General", func(ctx *CommandContext) {
 		if ctx.HasArgs() { // User passed an argument, give help information on that command only.
-			cmd := bot.GetCommand(ctx.Args[0].AsString())
+			cmd := bot.GetCommand(strings.ToLower(ctx.Args[0].AsString()))
 			if cmd == nil {
-				ctx.Reply("Unknown Command.")
+				if names := bot.SuggestCommands(ctx.Args[0].AsString(), ctx.Author.ID); len(names) > 0 {
+					ctx.ReplyLocale("COMMAND_UNKNOWN_SUGGESTION", formatSuggestions(ctx.Prefix+ctx.InvokedName+" ", names))
+					return
+				}
+				ctx.ReplyLocale("COMMAND_HELP_UNKNOWN")
 				return
 			}
-			var aliases string = "None"
+			// Walk down the subcommands, e.g help tag create
+			for _, name := range ctx.RawArgs[1:] {
+				sub := cmd.GetSubcommand(strings.ToLower(name))
+				if sub == nil {
+					break
+				}
+				cmd = sub
+			}
+			var aliases string = ctx.Localize("COMMAND_HELP_NO_ALIASES")
 
 			if len(cmd.Aliases) > 0 {
 				aliases = strings.Join(cmd.Aliases, ", ")
 			}
 
+			description := ctx.Localize("COMMAND_HELP_DESCRIPTION",
+				cmd.FullName(),
+				cmd.Description,
+				cmd.Category,
+				aliases,
+				fmt.Sprintf("%s%s %s", ctx.Prefix, cmd.FullName(), HumanizeUsage(cmd.UsageString)),
+			)
+
+			if len(cmd.Flags) > 0 {
+				description += "\n" + ctx.Localize("COMMAND_HELP_FLAGS") + "\n" + flagList(ctx, cmd)
+			}
+
+			if len(cmd.Subcommands) > 0 {
+				description += "\n" + ctx.Localize("COMMAND_HELP_SUBCOMMANDS") + "\n" + subcommandTree(ctx.Prefix, cmd, 0)
+			}
+
 			ctx.BuildEmbed(NewEmbed().
-				SetDescription(fmt.Sprintf("**Name:** %s\n**Description:** %s\n**Category:** %s\n**Aliases:** %s\n**Usage:** %s",
-					cmd.Name,
-					cmd.Description,
-					cmd.Category,
-					aliases,
-					fmt.Sprintf("%s%s %s", ctx.Prefix, cmd.Name, HumanizeUsage(cmd.UsageString)),
-				)).SetColor(bot.Color).SetTitle("Command Help"))
+				SetDescription(description).SetColor(bot.Color).SetTitle(ctx.Localize("COMMAND_HELP_TITLE")))
 			return
 		}
 		// Send all commands.
@@ -318,9 +514,9 @@ func (bot *Bot) LoadBuiltins() *Bot {
 		}
 
 		var embed = &discordgo.MessageEmbed{
-			Title:  "Commands",
+			Title:  ctx.Localize("COMMAND_HELP_COMMANDS"),
 			Color:  bot.Color,
-			Footer: &discordgo.MessageEmbedFooter{Text: "For more info on a command use: " + ctx.Prefix + "help <command>"},
+			Footer: &discordgo.MessageEmbedFooter{Text: ctx.Localize("COMMAND_HELP_FOOTER", ctx.Prefix+"help <command>")},
 			Author: &discordgo.MessageEmbedAuthor{IconURL: ctx.Author.AvatarURL("256"), Name: ctx.Author.Username},
 		}
 
@@ -331,7 +527,7 @@ func (bot *Bot) LoadBuiltins() *Bot {
 			embed.Fields = append(embed.Fields, field)
 		}
 		ctx.ReplyEmbed(embed)
-	}).SetDescription("Shows a list of all commands.").SetUsage("[command:string]").AddAliases("h", "cmds", "commands"))
+	}).SetDescription("Shows a list of all commands.").SetUsage("[command:string...]").AddAliases("h", "cmds", "commands"))
 
 	bot.AddCommand(NewCommand("stats", "General", func(ctx *CommandContext) {
 		// Runtime stats, these stats for some reason makes me feel really good.
@@ -346,15 +542,15 @@ func (bot *Bot) LoadBuiltins() *Bot {
 		}
 
 		ctx.BuildEmbed(NewEmbed().
-			SetTitle("Stats").
+			SetTitle(ctx.Localize("COMMAND_STATS_TITLE")).
 			SetAuthor(ctx.Session.State.User.Username, ctx.Session.State.User.AvatarURL("256")).
 			SetColor(bot.Color).
-			AddField("Go Version", strings.TrimPrefix(runtime.Version(), "go")).
-			AddField("DiscordGo Version", discordgo.VERSION).
-			AddField("Sapphire Version", VERSION).
-			AddField("Bot Stats", fmt.Sprintf("**Guilds:** %d\n**Users:** %d\n**Channels:** %d\n**Uptime:** %s", guilds, users, channels, humanize.RelTime(bot.Uptime, time.Now(), "", ""))).
-			AddField("Command Stats", fmt.Sprintf("**Total Commands:** %d\n**Commands Ran:** %d", len(bot.Commands), bot.CommandsRan)).
-			AddField("Memory Stats", fmt.Sprintf("**Used:** %s / %s\n**Garbage Collected:** %s\n**GC Cycles:** %d\n**Forced GC Cycles:** %d\n**Last GC:** %s\n**Next GC Target:** %s\n**Goroutines:** %d",
+			AddField(ctx.Localize("COMMAND_STATS_GO_VERSION"), strings.TrimPrefix(runtime.Version(), "go")).
+			AddField(ctx.Localize("COMMAND_STATS_DISCORDGO_VERSION"), discordgo.VERSION).
+			AddField(ctx.Localize("COMMAND_STATS_SAPPHIRE_VERSION"), VERSION).
+			AddField(ctx.Localize("COMMAND_STATS_BOT_TITLE"), ctx.Localize("COMMAND_STATS_BOT", guilds, users, channels, humanize.RelTime(bot.Uptime, time.Now(), "", ""))).
+			AddField(ctx.Localize("COMMAND_STATS_COMMANDS_TITLE"), ctx.Localize("COMMAND_STATS_COMMANDS", len(bot.Commands), atomic.LoadInt64(&bot.CommandsRan))).
+			AddField(ctx.Localize("COMMAND_STATS_MEMORY_TITLE"), ctx.Localize("COMMAND_STATS_MEMORY",
 				humanize.Bytes(stats.Alloc),
 				humanize.Bytes(stats.Sys),
 				humanize.Bytes(stats.TotalAlloc-stats.Alloc),
@@ -364,7 +560,7 @@ func (bot *Bot) LoadBuiltins() *Bot {
 				humanize.Bytes(stats.NextGC),
 				runtime.NumGoroutine(),
 			)).
-			AddField("Technical Info", fmt.Sprintf("**CPU Cores:** %d\n**OS/Arch:** %s/%s",
+			AddField(ctx.Localize("COMMAND_STATS_TECHNICAL_TITLE"), ctx.Localize("COMMAND_STATS_TECHNICAL",
 				runtime.NumCPU(),
 				runtime.GOOS,
 				runtime.GOARCH,
@@ -382,7 +578,7 @@ func (bot *Bot) LoadBuiltins() *Bot {
 			ctx.ReplyLocale("COMMAND_NOT_FOUND", ctx.Arg(0))
 			return
 		}
-		if command.Enabled {
+		if command.enabled() {
 			ctx.ReplyLocale("COMMAND_ENABLE_ALREADY")
 			return
 		}
@@ -397,7 +593,7 @@ func (bot *Bot) LoadBuiltins() *Bot {
 			return
 		}
 
-		if !command.Enabled {
+		if !command.enabled() {
 			ctx.ReplyLocale("COMMAND_DISABLE_ALREADY")
 			return
 		}
@@ -408,14 +604,12 @@ func (bot *Bot) LoadBuiltins() *Bot {
 	bot.AddCommand(NewCommand("gc", "Owner", func(ctx *CommandContext) {
 		before := &runtime.MemStats{}
 		runtime.ReadMemStats(before)
-		// Additionally we will collect extra garbage by freeing these stuff aswell, since this command is meant to be ran
-		// in memory critical situations losing them doesn't hurt at all.
-		bot.CommandCooldowns = make(map[string]map[string]time.Time)
-		bot.CommandEdits = make(map[string]string)
+		// Additionally we will collect extra garbage by evicting the expired cooldowns and edits without waiting for the next sweep.
+		bot.sweep()
 		runtime.GC()
 		after := &runtime.MemStats{}
 		runtime.ReadMemStats(after)
-		ctx.Reply("Forced Garbage Collection.\n  - Freed **%s**\n  - %d Objects Collected.\n  - Took **%d**μs",
+		ctx.ReplyLocale("COMMAND_GC",
 			humanize.Bytes(before.Alloc-after.Alloc), after.Frees-before.Frees, after.PauseTotalNs-before.PauseTotalNs)
 	}).SetDescription("Forces a garbage collection cycle.").AddAliases("garbagecollect", "forcegc", "runtime.GC()").SetOwnerOnly(true))
 	return bot
@@ -0,0 +1,373 @@
+// Package sapphiretest drives a sapphire bot without a discord connection so commands can be tested.
+//
+// The harness seeds the session's state with fake guilds, channels, roles and members, messages are injected
+// through the monitors like they came from discord and everything the bot does is recorded in a transcript
+// by a local stand-in of the discord API.
+//
+//	h := sapphiretest.New()
+//	defer h.Close()
+//	h.Bot.SetPrefix("!").LoadBuiltins()
+//	h.AddUser("2", "user")
+//	h.AddChannel("", "3", "dm")
+//	entries := h.Send("3", "2", "!ping")
+package sapphiretest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sapphire-cord/sapphire"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BotID is the user ID of the bot in the harness.
+const BotID = "100000000000000000"
+
+// EntryKind is the kind of action the bot did.
+type EntryKind string
+
+const (
+	Sent    EntryKind = "sent"    // A message was sent.
+	Edited  EntryKind = "edited"  // A message was edited.
+	Deleted EntryKind = "deleted" // A message was deleted.
+	Reacted EntryKind = "reacted" // A reaction was added to a message.
+)
+
+// Entry is an action the bot did, recorded in the transcript.
+type Entry struct {
+	Kind      EntryKind
+	ChannelID string
+	MessageID string                    // The ID of the message that was sent/edited/deleted/reacted to.
+	Content   string                    // Content of sent/edited messages.
+	Embeds    []*discordgo.MessageEmbed // Embeds of sent/edited messages.
+	Files     []string                  // Names of the files of sent messages.
+	Emoji     string                    // The emoji of a reaction.
+}
+
+// Harness is a bot connected to a local stand-in of the discord API.
+type Harness struct {
+	Bot        *sapphire.Bot
+	Session    *discordgo.Session
+	server     *httptest.Server
+	users      map[string]*discordgo.User
+	transcript []*Entry
+	messages   map[string]*discordgo.Message
+	lastID     int64
+	lock       sync.Mutex
+}
+
+// New creates a new harness with a fresh bot, the bot's user is already in the state with the ID BotID.
+func New() *Harness {
+	h := &Harness{
+		users:    make(map[string]*discordgo.User),
+		messages: make(map[string]*discordgo.Message),
+	}
+	h.server = httptest.NewServer(http.HandlerFunc(h.serve))
+	serverURL, _ := url.Parse(h.server.URL)
+
+	s, _ := discordgo.New("Bot sapphiretest")
+	s.Client = &http.Client{Transport: &transport{url: serverURL}}
+	s.State.User = &discordgo.User{ID: BotID, Username: "sapphire", Bot: true}
+	h.users[BotID] = s.State.User
+	h.Session = s
+	h.Bot = sapphire.New(s)
+	return h
+}
+
+// Close shuts down the API stand-in.
+func (h *Harness) Close() {
+	h.server.Close()
+}
+
+// AddUser adds a user that can send messages, users in DMs only need this while users in guilds need AddMember.
+func (h *Harness) AddUser(id, username string) *discordgo.User {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	user := &discordgo.User{ID: id, Username: username, Discriminator: "0001"}
+	h.users[id] = user
+	return user
+}
+
+// AddGuild adds a guild owned by ownerID, the @everyone role is created with the permissions in everyone.
+// The bot is added as a member of the guild.
+func (h *Harness) AddGuild(id, name, ownerID string, everyone int64) *discordgo.Guild {
+	guild := &discordgo.Guild{ID: id, Name: name, OwnerID: ownerID}
+	h.Session.State.GuildAdd(guild)
+	h.AddRole(id, id, "@everyone", everyone)
+	h.AddMember(id, BotID, "sapphire")
+	guild, _ = h.Session.State.Guild(id)
+	return guild
+}
+
+// AddChannel adds a text channel to the guild with guildID, an empty guildID adds a DM channel.
+func (h *Harness) AddChannel(guildID, id, name string) *discordgo.Channel {
+	channel := &discordgo.Channel{ID: id, GuildID: guildID, Name: name, Type: discordgo.ChannelTypeGuildText}
+	if guildID == "" {
+		channel.Type = discordgo.ChannelTypeDM
+	}
+	h.Session.State.ChannelAdd(channel)
+	return channel
+}
+
+// AddRole adds a role to the guild with guildID.
+func (h *Harness) AddRole(guildID, id, name string, permissions int64) *discordgo.Role {
+	role := &discordgo.Role{ID: id, Name: name, Permissions: permissions}
+	h.Session.State.RoleAdd(guildID, role)
+	return role
+}
+
+// AddMember adds a member to the guild with guildID, the user is created if it doesn't exist.
+func (h *Harness) AddMember(guildID, userID, username string, roles ...string) *discordgo.Member {
+	h.lock.Lock()
+	user, ok := h.users[userID]
+	h.lock.Unlock()
+	if !ok {
+		user = h.AddUser(userID, username)
+	}
+	member := &discordgo.Member{GuildID: guildID, User: user, Roles: roles}
+	h.Session.State.MemberAdd(member)
+	return member
+}
+
+// NewMessage creates a message sent by authorID in the channel with channelID without dispatching it.
+// Panics if the author or the channel don't exist.
+func (h *Harness) NewMessage(channelID, authorID, content string) *discordgo.Message {
+	channel, err := h.Session.State.Channel(channelID)
+	if err != nil {
+		panic(fmt.Sprintf("sapphiretest: the channel '%s' doesn't exist.", channelID))
+	}
+	h.lock.Lock()
+	author, ok := h.users[authorID]
+	h.lock.Unlock()
+	if !ok {
+		panic(fmt.Sprintf("sapphiretest: the user '%s' doesn't exist.", authorID))
+	}
+	m := &discordgo.Message{
+		ID:        h.nextID(),
+		ChannelID: channelID,
+		GuildID:   channel.GuildID,
+		Author:    author,
+		Content:   content,
+		Timestamp: time.Now(),
+	}
+	if member, err := h.Session.State.Member(channel.GuildID, authorID); err == nil {
+		m.Member = member
+	}
+	return m
+}
+
+// Dispatch runs the monitors for m and returns the entries recorded while they ran.
+func (h *Harness) Dispatch(m *discordgo.Message) []*Entry {
+	return h.record(func() {
+		h.Bot.HandleMessage(m, false)
+	})
+}
+
+// DispatchEdit edits the content of m and runs the monitors for the edit, returns the entries recorded while they ran.
+func (h *Harness) DispatchEdit(m *discordgo.Message, content string) []*Entry {
+	edited := *m
+	edited.Content = content
+	return h.record(func() {
+		h.Bot.HandleMessage(&edited, true)
+	})
+}
+
+// React adds a reaction by userID to the message with messageID and hands it to whoever is waiting for it,
+// returns the entries recorded while they ran.
+func (h *Harness) React(channelID, messageID, userID, emoji string) []*Entry {
+	return h.record(func() {
+		h.Bot.HandleReaction(&discordgo.MessageReaction{
+			UserID:    userID,
+			MessageID: messageID,
+			ChannelID: channelID,
+			Emoji:     discordgo.Emoji{Name: emoji},
+		})
+	})
+}
+
+// Send creates a message with NewMessage and dispatches it.
+func (h *Harness) Send(channelID, authorID, content string) []*Entry {
+	return h.Dispatch(h.NewMessage(channelID, authorID, content))
+}
+
+// Transcript returns everything the bot did so far.
+func (h *Harness) Transcript() []*Entry {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]*Entry{}, h.transcript...)
+}
+
+// Runs fn and returns the entries recorded while it ran.
+func (h *Harness) record(fn func()) []*Entry {
+	h.lock.Lock()
+	start := len(h.transcript)
+	h.lock.Unlock()
+	fn()
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]*Entry{}, h.transcript[start:]...)
+}
+
+// Generates a snowflake for the current time so timestamps can be derived from IDs.
+func (h *Harness) nextID() string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	id := (time.Now().UnixNano()/int64(time.Millisecond) - 1420070400000) << 22
+	if id <= h.lastID {
+		id = h.lastID + 1
+	}
+	h.lastID = id
+	return fmt.Sprint(id)
+}
+
+// Redirects every request of the session to the stand-in.
+type transport struct {
+	url *url.URL
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.url.Scheme
+	req.URL.Host = t.url.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+var (
+	messagesRoute = regexp.MustCompile("^/api/v\\d+/channels/(\\d+)/messages$")
+	messageRoute  = regexp.MustCompile("^/api/v\\d+/channels/(\\d+)/messages/(\\d+)$")
+	reactionRoute = regexp.MustCompile("^/api/v\\d+/channels/(\\d+)/messages/(\\d+)/reactions/([^/]+)/@me$")
+	typingRoute   = regexp.MustCompile("^/api/v\\d+/channels/(\\d+)/typing$")
+	userRoute     = regexp.MustCompile("^/api/v\\d+/users/(\\d+)$")
+	memberRoute   = regexp.MustCompile("^/api/v\\d+/guilds/(\\d+)/members/(\\d+)$")
+)
+
+// The discord API stand-in, it only implements what sapphire uses.
+func (h *Harness) serve(w http.ResponseWriter, r *http.Request) {
+	// Reaction emojis are escaped in the path.
+	path := r.URL.EscapedPath()
+
+	switch {
+	case r.Method == "POST" && messagesRoute.MatchString(path):
+		match := messagesRoute.FindStringSubmatch(path)
+		data, files := readMessage(r)
+		msg := &discordgo.Message{
+			ID:        h.nextID(),
+			ChannelID: match[1],
+			Embeds:    data.Embeds,
+			Author:    h.Session.State.User,
+			Timestamp: time.Now(),
+		}
+		if data.Content != nil {
+			msg.Content = *data.Content
+		}
+		h.lock.Lock()
+		h.messages[msg.ID] = msg
+		h.transcript = append(h.transcript, &Entry{Kind: Sent, ChannelID: msg.ChannelID, MessageID: msg.ID, Content: msg.Content, Embeds: msg.Embeds, Files: files})
+		h.lock.Unlock()
+		writeJSON(w, msg)
+	case r.Method == "PATCH" && messageRoute.MatchString(path):
+		match := messageRoute.FindStringSubmatch(path)
+		data, _ := readMessage(r)
+		h.lock.Lock()
+		msg, ok := h.messages[match[2]]
+		if ok {
+			// Only the fields sent are edited.
+			if data.Content != nil {
+				msg.Content = *data.Content
+			}
+			if data.Embeds != nil {
+				msg.Embeds = data.Embeds
+			}
+			h.transcript = append(h.transcript, &Entry{Kind: Edited, ChannelID: match[1], MessageID: msg.ID, Content: msg.Content, Embeds: msg.Embeds})
+		}
+		h.lock.Unlock()
+		if !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, msg)
+	case r.Method == "DELETE" && messageRoute.MatchString(path):
+		match := messageRoute.FindStringSubmatch(path)
+		h.lock.Lock()
+		delete(h.messages, match[2])
+		h.transcript = append(h.transcript, &Entry{Kind: Deleted, ChannelID: match[1], MessageID: match[2]})
+		h.lock.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PUT" && reactionRoute.MatchString(path):
+		match := reactionRoute.FindStringSubmatch(path)
+		emoji, _ := url.PathUnescape(match[3])
+		h.lock.Lock()
+		h.transcript = append(h.transcript, &Entry{Kind: Reacted, ChannelID: match[1], MessageID: match[2], Emoji: emoji})
+		h.lock.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "POST" && typingRoute.MatchString(path):
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "GET" && userRoute.MatchString(path):
+		h.lock.Lock()
+		user, ok := h.users[userRoute.FindStringSubmatch(path)[1]]
+		h.lock.Unlock()
+		if !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, user)
+	case r.Method == "GET" && memberRoute.MatchString(path):
+		match := memberRoute.FindStringSubmatch(path)
+		member, err := h.Session.State.Member(match[1], match[2])
+		if err != nil {
+			notFound(w)
+			return
+		}
+		writeJSON(w, member)
+	default:
+		notFound(w)
+	}
+}
+
+// The fields of sent and edited messages we care about, content is a pointer to tell apart an edit that didn't change it.
+type messageData struct {
+	Content *string                   `json:"content"`
+	Embeds  []*discordgo.MessageEmbed `json:"embeds"`
+	Embed   *discordgo.MessageEmbed   `json:"embed"`
+}
+
+// Reads a message from the body, messages with files are sent as multipart with the message in payload_json.
+func readMessage(r *http.Request) (*messageData, []string) {
+	var body []byte
+	var files []string
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		if err := r.ParseMultipartForm(32 << 20); err == nil {
+			body = []byte(r.FormValue("payload_json"))
+			for _, headers := range r.MultipartForm.File {
+				for _, header := range headers {
+					files = append(files, header.Filename)
+				}
+			}
+		}
+	} else {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+	data := &messageData{}
+	json.Unmarshal(body, data)
+	if data.Embed != nil {
+		data.Embeds = append(data.Embeds, data.Embed)
+	}
+	return data, files
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"code":10013,"message":"Unknown"}`))
+}
@@ -0,0 +1,361 @@
+package sapphiretest_test
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/sapphire-cord/sapphire"
+	"github.com/sapphire-cord/sapphire/sapphiretest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newHarness(t *testing.T) *sapphiretest.Harness {
+	h := sapphiretest.New()
+	t.Cleanup(h.Close)
+	h.Bot.SetPrefix("!").LoadBuiltins()
+	h.AddGuild("200000000000000000", "Test Server", "300000000000000000", discordgo.PermissionViewChannel|discordgo.PermissionSendMessages)
+	h.AddChannel("200000000000000000", "400000000000000000", "general")
+	h.AddMember("200000000000000000", "300000000000000000", "owner")
+	h.AddMember("200000000000000000", "500000000000000000", "member")
+	return h
+}
+
+func TestPing(t *testing.T) {
+	h := newHarness(t)
+	entries := h.Send("400000000000000000", "500000000000000000", "!ping")
+	if len(entries) != 2 {
+		t.Fatalf("Expected a message and an edit but got %d entries", len(entries))
+	}
+	if entries[0].Kind != sapphiretest.Sent || entries[0].Content != "Pong!" {
+		t.Errorf("Expected Pong! to be sent but got %+v", entries[0])
+	}
+	if entries[1].Kind != sapphiretest.Edited || entries[1].MessageID != entries[0].MessageID || !strings.HasPrefix(entries[1].Content, "Pong! Latency:") {
+		t.Errorf("Expected Pong! to be edited with the latency but got %+v", entries[1])
+	}
+}
+
+func TestCommand(t *testing.T) {
+	h := newHarness(t)
+	h.Bot.AddCommand(sapphire.NewCommand("greet", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Hello %s!", ctx.Arg(0).AsMember().User.Username)
+		ctx.React("👋")
+	}).SetUsage("<@@member>").SetRequiredPermissions(discordgo.PermissionManageMessages))
+
+	msg := h.NewMessage("400000000000000000", "300000000000000000", "!greet <@500000000000000000>")
+	entries := h.Dispatch(msg)
+	if len(entries) != 2 || entries[0].Content != "Hello member!" || entries[1].Kind != sapphiretest.Reacted || entries[1].Emoji != "👋" {
+		t.Fatalf("Unexpected entries %+v", entries)
+	}
+
+	// Editing the command edits the response.
+	entries = h.DispatchEdit(msg, "!greet <@300000000000000000>")
+	if len(entries) < 1 || entries[0].Kind != sapphiretest.Edited || entries[0].MessageID != h.Transcript()[0].MessageID || entries[0].Content != "Hello owner!" {
+		t.Errorf("Expected the response to be edited but got %+v", entries)
+	}
+
+	entries = h.Send("400000000000000000", "500000000000000000", "!greet <@300000000000000000>")
+	if len(entries) != 1 || !strings.Contains(entries[0].Content, "Manage Messages") {
+		t.Errorf("Expected the missing permissions to be reported but got %+v", entries)
+	}
+}
+
+func TestQuotedArguments(t *testing.T) {
+	h := newHarness(t)
+	h.Bot.AddCommand(sapphire.NewCommand("tag", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("%s: %s (%s)", ctx.Arg(0).AsString(), ctx.JoinedArgs(1), ctx.Flags["mode"])
+	}).SetUsage("<name:string> <content:string...>"))
+
+	entries := h.Send("400000000000000000", "500000000000000000", "!tag “hello world” first --mode=\"a b\"  line\nsecond")
+	if len(entries) != 1 || entries[0].Content != "hello world: first  line\nsecond (a b)" {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+}
+
+func TestNameResolution(t *testing.T) {
+	h := newHarness(t)
+	h.AddMember("200000000000000000", "600000000000000000", "memo")
+	h.Bot.AddCommand(sapphire.NewCommand("greet", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Hello %s!", ctx.Arg(0).AsMember().User.Username)
+	}).SetUsage("<@@member>"))
+
+	entries := h.Send("400000000000000000", "500000000000000000", "!greet owner")
+	if len(entries) != 1 || entries[0].Content != "Hello owner!" {
+		t.Fatalf("Expected the member to be found by name but got %+v", entries)
+	}
+
+	// Both member and memo start with mem.
+	entries = h.Send("400000000000000000", "500000000000000000", "!greet mem")
+	if len(entries) != 1 || !strings.Contains(entries[0].Content, "which one did you mean?") || !strings.Contains(entries[0].Content, "2. memo") {
+		t.Fatalf("Expected to be asked which member but got %+v", entries)
+	}
+
+	// Other users can't answer and the answer doesn't run as a command.
+	entries = h.Send("400000000000000000", "300000000000000000", "1")
+	if len(entries) != 0 {
+		t.Fatalf("Expected nothing to happen but got %+v", entries)
+	}
+	entries = h.Send("400000000000000000", "500000000000000000", "2")
+	if len(entries) != 1 || entries[0].Kind != sapphiretest.Edited || entries[0].Content != "Hello memo!" {
+		t.Fatalf("Expected the prompt to be edited with the answer but got %+v", entries)
+	}
+
+	h.Send("400000000000000000", "500000000000000000", "!greet mem")
+	entries = h.Send("400000000000000000", "500000000000000000", "cancel")
+	if len(entries) != 1 || entries[0].Content != "Cancelled." {
+		t.Errorf("Expected the prompt to be cancelled but got %+v", entries)
+	}
+
+	// Optionals are skipped without asking when the name could be for the next argument.
+	h.Bot.AddCommand(sapphire.NewCommand("ban", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Banned %t: %s (%d words)", ctx.Arg(0).IsProvided(), ctx.Arg(1).AsString(), len(ctx.Args)-1)
+	}).SetUsage("[target:member] <reason:string...>"))
+	entries = h.Send("400000000000000000", "500000000000000000", "!ban mem being rude")
+	if len(entries) != 1 || entries[0].Content != "Banned false: mem (3 words)" {
+		t.Errorf("Expected the optional member to be skipped without a prompt but got %+v", entries[0])
+	}
+
+	// In DMs only the guilds the author shares with the bot are searched.
+	h.AddGuild("210000000000000000", "Secret Server", "300000000000000000", 0)
+	h.AddMember("210000000000000000", "610000000000000000", "secret")
+	h.AddChannel("", "410000000000000000", "dm")
+	h.AddUser("700000000000000000", "stranger")
+	h.Bot.AddCommand(sapphire.NewCommand("whois", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply(ctx.Arg(0).AsUser().ID)
+	}).SetUsage("<user:user>"))
+
+	entries = h.Send("410000000000000000", "700000000000000000", "!whois secret")
+	if len(entries) != 1 || strings.Contains(entries[0].Content, "610000000000000000") {
+		t.Errorf("Expected a stranger to not find members of other guilds but got %+v", entries)
+	}
+	entries = h.Send("410000000000000000", "500000000000000000", "!whois memo")
+	if len(entries) != 1 || entries[0].Content != "600000000000000000" {
+		t.Errorf("Expected a member to find members of shared guilds but got %+v", entries)
+	}
+}
+
+func TestPrompting(t *testing.T) {
+	h := newHarness(t)
+	h.Bot.AddCommand(sapphire.NewCommand("give", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Gave %d to %s: %s", ctx.Arg(0).AsInt(), ctx.Arg(1).AsMember().User.Username, ctx.JoinedArgs(2))
+	}).SetUsage("<amount:int{1,100}> <@@member> <note:string...>").SetPrompting(true))
+
+	entries := h.Send("400000000000000000", "500000000000000000", "!give 500")
+	if len(entries) != 1 || !strings.HasPrefix(entries[0].Content, "**amount** must be between 1 and 100. Please try again") {
+		t.Fatalf("Expected the invalid argument to be asked again but got %+v", entries)
+	}
+	entries = h.Send("400000000000000000", "500000000000000000", "50")
+	if len(entries) != 1 || !strings.HasPrefix(entries[0].Content, "Please provide **member**") {
+		t.Fatalf("Expected the missing argument to be asked but got %+v", entries)
+	}
+	entries = h.Send("400000000000000000", "500000000000000000", "owner")
+	if len(entries) != 1 || !strings.HasPrefix(entries[0].Content, "Please provide **note**") {
+		t.Fatalf("Expected the missing rest to be asked but got %+v", entries)
+	}
+	entries = h.Send("400000000000000000", "500000000000000000", "for being nice")
+	if len(entries) != 1 || entries[0].Content != "Gave 50 to owner: for being nice" {
+		t.Fatalf("Expected the command to run after the answers but got %+v", entries)
+	}
+
+	h.Send("400000000000000000", "500000000000000000", "!give")
+	entries = h.Send("400000000000000000", "500000000000000000", "Cancel")
+	if len(entries) != 1 || entries[0].Content != "Cancelled." {
+		t.Fatalf("Expected the prompt to be cancelled but got %+v", entries)
+	}
+
+	// Cancelled prompts don't swallow the next message.
+	entries = h.Send("400000000000000000", "500000000000000000", "!give 1 owner thanks")
+	if len(entries) != 1 || entries[0].Content != "Gave 1 to owner: thanks" {
+		t.Fatalf("Expected the command to run but got %+v", entries)
+	}
+
+	h.Bot.SetPromptTimeout(10 * time.Millisecond)
+	entries = h.Send("400000000000000000", "500000000000000000", "!give")
+	time.Sleep(50 * time.Millisecond)
+	if transcript := h.Transcript(); transcript[len(transcript)-1].Content != "You took too long to answer." {
+		t.Errorf("Expected the prompt to time out but got %+v", transcript[len(transcript)-1])
+	}
+}
+
+func TestDeclaredFlags(t *testing.T) {
+	h := newHarness(t)
+	h.Bot.AddCommand(sapphire.NewCommand("ban", "Moderation", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Banned %s for %s, deleting %d days (forced: %t)", ctx.Arg(0).AsUser().Username,
+			ctx.JoinedArgs(1), ctx.FlagArg("days").AsInt(), ctx.FlagArg("force").AsBool())
+	}).SetUsage("<@member> <reason:string...>").
+		AddFlag("days", "int{0,7}", "1", "Days of messages to delete.").
+		AddFlag("force", "bool", "", "Skip the confirmation.").
+		AddFlagAlias("days", "d"))
+
+	entries := h.Send("400000000000000000", "500000000000000000", "!ban owner spamming -d 3 --force links")
+	if len(entries) != 1 || entries[0].Content != "Banned owner for spamming links, deleting 3 days (forced: true)" {
+		t.Fatalf("Expected the flags to be parsed but got %+v", entries)
+	}
+
+	entries = h.Send("400000000000000000", "500000000000000000", "!ban owner spamming")
+	if len(entries) != 1 || entries[0].Content != "Banned owner for spamming, deleting 1 days (forced: false)" {
+		t.Fatalf("Expected the defaults but got %+v", entries)
+	}
+
+	entries = h.Send("400000000000000000", "500000000000000000", "!ban owner spamming --days=9")
+	if len(entries) != 1 || !strings.Contains(entries[0].Content, "between 0 and 7") {
+		t.Fatalf("Expected the constraint to fail but got %+v", entries)
+	}
+
+	entries = h.Send("400000000000000000", "500000000000000000", "!ban owner spamming --dayz=3")
+	if len(entries) != 1 || entries[0].Content != "Unknown flags: **--dayz**" {
+		t.Fatalf("Expected the typo to be reported but got %+v", entries)
+	}
+
+	// Flags are checked before the arguments.
+	entries = h.Send("400000000000000000", "500000000000000000", "!ban --dayz=3")
+	if len(entries) != 1 || entries[0].Content != "Unknown flags: **--dayz**" {
+		t.Fatalf("Expected the typo to be reported before the missing arguments but got %+v", entries)
+	}
+
+	entries = h.Send("400000000000000000", "500000000000000000", "!ban owner spamming —again -x")
+	if len(entries) != 1 || entries[0].Content != "Banned owner for spamming —again -x, deleting 1 days (forced: false)" {
+		t.Fatalf("Expected single dash words to be text but got %+v", entries)
+	}
+
+	h.Bot.SetUnknownFlags(sapphire.UnknownFlagsKeep)
+	entries = h.Send("400000000000000000", "500000000000000000", "!ban owner spamming --dayz=3")
+	if len(entries) != 1 || entries[0].Content != "Banned owner for spamming --dayz=3, deleting 1 days (forced: false)" {
+		t.Fatalf("Expected the unknown flag to be kept but got %+v", entries)
+	}
+
+	entries = h.Send("400000000000000000", "500000000000000000", "!help ban")
+	if len(entries) != 1 || len(entries[0].Embeds) != 1 || !strings.Contains(entries[0].Embeds[0].Description, "`--days, -d <int{0,7}>` - Days of messages to delete. (default: 1)") {
+		t.Errorf("Expected the flags in the help but got %+v", entries)
+	}
+}
+
+func TestCommandSuggestions(t *testing.T) {
+	h := newHarness(t)
+	h.Bot.AddCommand(sapphire.NewCommand("echo", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply(ctx.JoinedArgs())
+	}))
+
+	// Off by default.
+	if entries := h.Send("400000000000000000", "500000000000000000", "!hlep"); len(entries) != 0 {
+		t.Fatalf("Expected no suggestions by default but got %+v", entries)
+	}
+
+	h.Bot.SetSuggestions(sapphire.SuggestionsReply)
+	entries := h.Send("400000000000000000", "500000000000000000", "!hlep")
+	if len(entries) != 1 || entries[0].Content != "Did you mean `!help`?" {
+		t.Fatalf("Expected a suggestion but got %+v", entries)
+	}
+	if entries := h.Send("400000000000000000", "500000000000000000", "!pnig"); len(entries) != 0 {
+		t.Fatalf("Expected suggestions to be rate limited but got %+v", entries)
+	}
+
+	entries = h.Send("400000000000000000", "500000000000000000", "!help ecoh")
+	if len(entries) != 1 || entries[0].Content != "Unknown Command. Did you mean `!help echo`?" {
+		t.Fatalf("Expected help to suggest the command but got %+v", entries)
+	}
+
+	h.Bot.SetSuggestions(sapphire.SuggestionsConfirm).SetSuggestCooldown(0)
+	entries = h.Send("400000000000000000", "500000000000000000", "!ecoh hello  world")
+	if len(entries) != 2 || entries[0].Content != "Did you mean `!echo`? React with ✅ to run it." ||
+		entries[1].Kind != sapphiretest.Reacted || entries[1].Emoji != sapphire.EmojiConfirm {
+		t.Fatalf("Expected a suggestion to confirm but got %+v", entries)
+	}
+
+	// Only the author can confirm.
+	if entries := h.React("400000000000000000", entries[0].MessageID, "300000000000000000", sapphire.EmojiConfirm); len(entries) != 0 {
+		t.Fatalf("Expected nothing to happen but got %+v", entries)
+	}
+	entries = h.React("400000000000000000", entries[0].MessageID, "500000000000000000", sapphire.EmojiConfirm)
+	if len(entries) != 1 || entries[0].Content != "hello  world" {
+		t.Fatalf("Expected the suggestion to run but got %+v", entries)
+	}
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	h := newHarness(t)
+	h.Bot.AddLanguage(sapphire.NewLanguage("de-DE").
+		Set("ARGUMENT_REQUIRED", "Das Argument **%s** wird benötigt.").
+		Set("ARGUMENT_MEMBER", "**%s** muss ein gültiges Mitglied sein."))
+	h.Bot.SetLocaleHandler(func(_ *sapphire.Bot, _ *discordgo.Message, _ bool) string {
+		return "de-DE"
+	})
+	h.Bot.AddCommand(sapphire.NewCommand("greet", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Hallo!")
+	}).SetUsage("<target:member> <count:int>"))
+
+	entries := h.Send("400000000000000000", "500000000000000000", "!greet")
+	if len(entries) != 1 || entries[0].Content != "Das Argument **target** wird benötigt." {
+		t.Fatalf("Expected the translated error but got %+v", entries)
+	}
+	entries = h.Send("400000000000000000", "500000000000000000", "!greet nobody 1")
+	if len(entries) != 1 || entries[0].Content != "**target** muss ein gültiges Mitglied sein." {
+		t.Fatalf("Expected the translated error but got %+v", entries)
+	}
+
+	// Keys the language doesn't have fall back to the default locale.
+	entries = h.Send("400000000000000000", "500000000000000000", "!greet owner many")
+	if len(entries) != 1 || entries[0].Content != "**count** must be a valid whole number." {
+		t.Errorf("Expected the english error but got %+v", entries)
+	}
+}
+
+func TestCooldownAfterParsing(t *testing.T) {
+	h := newHarness(t)
+	h.Bot.AddCommand(sapphire.NewCommand("roll", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Rolled %d", ctx.Arg(0).AsInt())
+	}).SetUsage("<sides:int>").SetCooldownBucket(sapphire.CooldownUser, 1, time.Minute))
+
+	// A typo doesn't use the cooldown.
+	h.Send("400000000000000000", "500000000000000000", "!roll six")
+	entries := h.Send("400000000000000000", "500000000000000000", "!roll 6")
+	if len(entries) != 1 || entries[0].Content != "Rolled 6" {
+		t.Fatalf("Expected the command to run after a failed parse but got %+v", entries)
+	}
+	entries = h.Send("400000000000000000", "500000000000000000", "!roll 6")
+	if len(entries) != 1 || !strings.Contains(entries[0].Content, "You can use this command again in") {
+		t.Errorf("Expected the second use to be on cooldown but got %+v", entries)
+	}
+}
+
+func TestCooldownField(t *testing.T) {
+	h := newHarness(t)
+	cmd := sapphire.NewCommand("daily", "General", func(ctx *sapphire.CommandContext) {
+		ctx.Reply("Claimed")
+	})
+	cmd.Cooldown = 60
+	h.Bot.AddCommand(cmd)
+
+	h.Send("400000000000000000", "500000000000000000", "!daily")
+	entries := h.Send("400000000000000000", "500000000000000000", "!daily")
+	if len(entries) != 1 || entries[0].Content != "You can use this command again in 60 seconds." {
+		t.Errorf("Expected the cooldown field to work with whole seconds but got %+v", entries)
+	}
+}
+
+// Run with -race, the enable and disable builtins toggle commands while they are dispatched.
+func TestToggleWhileDispatching(t *testing.T) {
+	h := newHarness(t)
+	ping := h.Bot.GetCommand("ping")
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				ping.Disable()
+				ping.Enable()
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		h.Send("400000000000000000", "500000000000000000", "!ping")
+	}
+	close(stop)
+	<-done
+	if !ping.IsEnabled() {
+		t.Error("Expected ping to end up enabled")
+	}
+}
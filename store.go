@@ -0,0 +1,147 @@
+package sapphire
+
+import (
+	"sync"
+	"time"
+)
+
+// Commands run concurrently so the state they share is kept behind these stores.
+// The defaults keep everything in memory but they can be replaced to use e.g a database.
+// Entries expire on their own, the bot calls Sweep periodically to evict the expired ones from memory.
+
+// CooldownStore keeps track of command cooldowns, implementations must be safe for concurrent use.
+type CooldownStore interface {
+	// Check takes a use from the bucket key and returns true if it has uses left.
+	// The bucket allows uses per window, the window starts with the first use and the bucket is refilled when it's over.
+	// If the bucket has no uses left it returns false and the time remaining until the window is over.
+	Check(key string, uses int, window time.Duration) (bool, time.Duration)
+	// Peek is like Check but doesn't take a use.
+	Peek(key string, uses int, window time.Duration) (bool, time.Duration)
+	// Clear removes all cooldowns.
+	Clear()
+	// Sweep evicts the buckets that their window is over.
+	Sweep()
+}
+
+// EditStore keeps track of the responses to commands so editing a command edits the response,
+// implementations must be safe for concurrent use.
+type EditStore interface {
+	// Get returns the ID of the response to the command message with messageID.
+	Get(messageID string) (string, bool)
+	// Set sets the ID of the response to the command message with messageID, it expires after ttl.
+	Set(messageID, responseID string, ttl time.Duration)
+	// Clear removes all responses.
+	Clear()
+	// Sweep evicts the expired responses.
+	Sweep()
+}
+
+// MemoryCooldownStore is the default CooldownStore, it keeps the cooldowns in memory.
+type MemoryCooldownStore struct {
+	cooldowns map[string]*cooldownEntry
+	lock      sync.Mutex
+}
+
+type cooldownEntry struct {
+	uses    int       // Uses taken in the current window.
+	expires time.Time // The end of the current window.
+}
+
+// NewMemoryCooldownStore creates a new empty MemoryCooldownStore.
+func NewMemoryCooldownStore() *MemoryCooldownStore {
+	return &MemoryCooldownStore{cooldowns: make(map[string]*cooldownEntry)}
+}
+
+func (s *MemoryCooldownStore) Check(key string, uses int, window time.Duration) (bool, time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	entry, ok := s.cooldowns[key]
+	if !ok || !now.Before(entry.expires) {
+		s.cooldowns[key] = &cooldownEntry{uses: 1, expires: now.Add(window)}
+		return true, 0
+	}
+	if entry.uses >= uses {
+		return false, entry.expires.Sub(now)
+	}
+	entry.uses++
+	return true, 0
+}
+
+func (s *MemoryCooldownStore) Peek(key string, uses int, window time.Duration) (bool, time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	entry, ok := s.cooldowns[key]
+	if !ok || !now.Before(entry.expires) || entry.uses < uses {
+		return true, 0
+	}
+	return false, entry.expires.Sub(now)
+}
+
+func (s *MemoryCooldownStore) Clear() {
+	s.lock.Lock()
+	s.cooldowns = make(map[string]*cooldownEntry)
+	s.lock.Unlock()
+}
+
+func (s *MemoryCooldownStore) Sweep() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for key, entry := range s.cooldowns {
+		if !now.Before(entry.expires) {
+			delete(s.cooldowns, key)
+		}
+	}
+}
+
+// MemoryEditStore is the default EditStore, it keeps the responses in memory.
+type MemoryEditStore struct {
+	edits map[string]*editEntry
+	lock  sync.RWMutex
+}
+
+type editEntry struct {
+	responseID string
+	expires    time.Time
+}
+
+// NewMemoryEditStore creates a new empty MemoryEditStore.
+func NewMemoryEditStore() *MemoryEditStore {
+	return &MemoryEditStore{edits: make(map[string]*editEntry)}
+}
+
+func (s *MemoryEditStore) Get(messageID string) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	entry, ok := s.edits[messageID]
+	// Expired entries may not be swept yet.
+	if !ok || !time.Now().Before(entry.expires) {
+		return "", false
+	}
+	return entry.responseID, true
+}
+
+func (s *MemoryEditStore) Set(messageID, responseID string, ttl time.Duration) {
+	s.lock.Lock()
+	s.edits[messageID] = &editEntry{responseID: responseID, expires: time.Now().Add(ttl)}
+	s.lock.Unlock()
+}
+
+func (s *MemoryEditStore) Clear() {
+	s.lock.Lock()
+	s.edits = make(map[string]*editEntry)
+	s.lock.Unlock()
+}
+
+func (s *MemoryEditStore) Sweep() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for id, entry := range s.edits {
+		if !now.Before(entry.expires) {
+			delete(s.edits, id)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package sapphire
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCooldownStore(t *testing.T) {
+	store := NewMemoryCooldownStore()
+	for i := 0; i < 3; i++ {
+		if ok, _ := store.Check("channel:ping", 3, time.Minute); !ok {
+			t.Errorf("Expected use %d to not be on cooldown", i+1)
+		}
+	}
+	if ok, after := store.Check("channel:ping", 3, time.Minute); ok || after <= 0 || after > time.Minute {
+		t.Errorf("Expected the fourth use to be on cooldown but got %v, %v", ok, after)
+	}
+	if ok, after := store.Peek("channel:ping", 3, time.Minute); ok || after <= 0 {
+		t.Errorf("Expected peeking an empty bucket to be on cooldown but got %v, %v", ok, after)
+	}
+	if ok, _ := store.Peek("peek:ping", 1, time.Minute); !ok {
+		t.Error("Expected peeking a new bucket to not be on cooldown")
+	}
+	if ok, _ := store.Check("peek:ping", 1, time.Minute); !ok {
+		t.Error("Expected peeking to not take a use")
+	}
+	if ok, _ := store.Check("other:ping", 3, time.Minute); !ok {
+		t.Error("Expected cooldowns to be per key")
+	}
+	store.Clear()
+	if ok, _ := store.Check("channel:ping", 3, time.Minute); !ok {
+		t.Error("Expected Clear to remove the cooldowns")
+	}
+
+	store.Check("short", 1, 10*time.Millisecond)
+	time.Sleep(15 * time.Millisecond)
+	if ok, _ := store.Check("short", 1, 10*time.Millisecond); !ok {
+		t.Error("Expected the bucket to be refilled after the window")
+	}
+}
+
+func TestMemoryStoresExpire(t *testing.T) {
+	cooldowns := NewMemoryCooldownStore()
+	cooldowns.Check("expired", 1, time.Millisecond)
+	cooldowns.Check("active", 1, time.Minute)
+	edits := NewMemoryEditStore()
+	edits.Set("expired", "1", time.Millisecond)
+	edits.Set("active", "2", time.Minute)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := edits.Get("expired"); ok {
+		t.Error("Expected an expired edit to not be returned before sweeping")
+	}
+	cooldowns.Sweep()
+	edits.Sweep()
+
+	if len(cooldowns.cooldowns) != 1 || len(edits.edits) != 1 {
+		t.Errorf("Expected only the active entries to survive sweeping but got %d cooldowns and %d edits", len(cooldowns.cooldowns), len(edits.edits))
+	}
+	if ok, _ := cooldowns.Check("active", 1, time.Minute); ok {
+		t.Error("Expected the active cooldown to survive sweeping")
+	}
+	if id, ok := edits.Get("active"); !ok || id != "2" {
+		t.Error("Expected the active edit to survive sweeping")
+	}
+}
+
+func TestSweepLoopStops(t *testing.T) {
+	bot := newTestBot(t)
+	bot.sweepTicker.Reset(time.Millisecond)
+	bot.CommandCooldowns.Check("expired", 1, time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		bot.sweepLoop()
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	bot.stopSweeping()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the sweep loop to return once sweeping is stopped")
+	}
+	if n := len(bot.CommandCooldowns.(*MemoryCooldownStore).cooldowns); n != 0 {
+		t.Errorf("Expected the expired cooldown to be swept but %d are left", n)
+	}
+}
+
+// Run with -race, commands share these stores from many goroutines.
+func TestStoresConcurrentAccess(t *testing.T) {
+	bot := newTestBot(t)
+	var wg sync.WaitGroup
+	var allowed int64
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if ok, _ := bot.CheckCooldown("user", "ping", 60); ok {
+				atomic.AddInt64(&allowed, 1)
+			}
+			id := fmt.Sprint(i)
+			bot.CommandEdits.Set(id, "response"+id, time.Minute)
+			// Another goroutine may have cleared it already, we only care that it doesn't race.
+			bot.CommandEdits.Get(id)
+			atomic.AddInt64(&bot.CommandsRan, 1)
+			if i%10 == 0 {
+				bot.CommandEdits.Clear()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if allowed != 1 {
+		t.Errorf("Expected exactly one concurrent use to pass the cooldown but %d did", allowed)
+	}
+	if bot.CommandsRan != 50 {
+		t.Errorf("Expected 50 commands ran but got %d", bot.CommandsRan)
+	}
+}
@@ -0,0 +1,128 @@
+package sapphire
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"sort"
+	"strings"
+)
+
+// What happens when someone runs a command that doesn't exist.
+type SuggestionMode int
+
+const (
+	SuggestionsOff     SuggestionMode = iota // Nothing happens.
+	SuggestionsReply                         // The closest commands are suggested, e.g "Did you mean `!help`?"
+	SuggestionsConfirm                       // Like SuggestionsReply but a single suggestion can be ran by reacting with EmojiConfirm.
+)
+
+// The reaction that runs a suggested command.
+const EmojiConfirm = "✅"
+
+// How many commands are suggested at most.
+const maxSuggestions = 3
+
+// SuggestCommands returns the names of the commands closest to input by edit distance, closest first.
+// Aliases are compared too and the suggestion is the name or alias that was closest.
+// Owner only commands are only suggested to the owner, pass an empty userID to only get public commands.
+// Disabled commands are never suggested.
+func (bot *Bot) SuggestCommands(input, userID string) []string {
+	input = strings.ToLower(input)
+	// Allow about one typo for every three characters, rounded up so swapped letters in short names are found. e.g hlep
+	limit := (len([]rune(input)) + 2) / 3
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	var suggestions []suggestion
+	for _, cmd := range bot.Commands {
+		// Suggesting a command that can't run only leads to another error.
+		if !cmd.IsEnabled() || (cmd.IsOwnerOnly() && (userID == "" || userID != bot.OwnerID)) {
+			continue
+		}
+		best := suggestion{distance: limit + 1}
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if d := levenshtein(input, strings.ToLower(name)); d < best.distance {
+				best = suggestion{name: name, distance: d}
+			}
+		}
+		if best.distance <= limit {
+			suggestions = append(suggestions, best)
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].name < suggestions[j].name
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	names := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Formats suggestions as code blocks with the prefix, e.g `!help`, `!hello`
+func formatSuggestions(prefix string, names []string) string {
+	formatted := make([]string, len(names))
+	for i, name := range names {
+		formatted[i] = "`" + prefix + name + "`"
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// Suggests the closest commands to input if enabled, at most once per channel every bot.SuggestCooldown.
+// rest is what came after the command so a confirmed suggestion runs with the same arguments.
+func (bot *Bot) suggestCommand(ctx *MonitorContext, prefix, input, rest string) {
+	if bot.Suggestions == SuggestionsOff {
+		return
+	}
+	names := bot.SuggestCommands(input, ctx.Author.ID)
+	if len(names) == 0 {
+		return
+	}
+	if bot.SuggestCooldown > 0 {
+		if ok, _ := bot.suggestLimits.Check(ctx.Channel.ID, 1, bot.SuggestCooldown); !ok {
+			return
+		}
+	}
+
+	cctx := &CommandContext{
+		Bot:         bot,
+		Message:     ctx.Message,
+		Channel:     ctx.Channel,
+		Session:     ctx.Session,
+		Author:      ctx.Author,
+		Prefix:      prefix,
+		Guild:       ctx.Guild,
+		Flags:       make(map[string]string),
+		InvokedName: input,
+		Locale:      bot.findLanguage(bot.Language(bot, ctx.Message, ctx.Channel.Type == discordgo.ChannelTypeDM)),
+	}
+
+	if bot.Suggestions != SuggestionsConfirm || len(names) > 1 {
+		cctx.ReplyNoEdit(cctx.Localize("COMMAND_SUGGESTION", formatSuggestions(prefix, names)))
+		return
+	}
+
+	msg, err := cctx.ReplyNoEdit(cctx.Localize("COMMAND_SUGGESTION_CONFIRM", formatSuggestions(prefix, names), EmojiConfirm))
+	if err != nil {
+		return
+	}
+	ctx.Session.MessageReactionAdd(msg.ChannelID, msg.ID, EmojiConfirm)
+	if !bot.awaitReaction(msg.ID, ctx.Author.ID, EmojiConfirm, bot.PromptTimeout, true) {
+		return
+	}
+
+	// Run it like the author typed it right.
+	message := *ctx.Message
+	message.Content = prefix + names[0] + rest
+	confirmed := *ctx
+	confirmed.Message = &message
+	CommandHandlerMonitor(bot, &confirmed)
+}
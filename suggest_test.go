@@ -0,0 +1,36 @@
+package sapphire
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggestCommands(t *testing.T) {
+	bot := newTestBot(t)
+	bot.OwnerID = "1"
+	bot.AddCommand(NewCommand("help", "General", func(ctx *CommandContext) {}))
+	bot.AddCommand(NewCommand("hello", "General", func(ctx *CommandContext) {}))
+	bot.AddCommand(NewCommand("ban", "Moderation", func(ctx *CommandContext) {}).AddAliases("hammer"))
+	bot.AddCommand(NewCommand("eval", "Owner", func(ctx *CommandContext) {}).SetOwnerOnly(true))
+	bot.AddCommand(NewCommand("kick", "Moderation", func(ctx *CommandContext) {}).Disable())
+
+	cases := []struct {
+		input    string
+		userID   string
+		expected []string
+	}{
+		{"hlep", "2", []string{"help"}},
+		{"helo", "2", []string{"hello", "help"}},
+		{"HAMMR", "2", []string{"hammer"}},
+		{"evl", "2", []string{}},
+		{"evl", "1", []string{"eval"}},
+		{"something", "2", []string{}},
+		{"kik", "1", []string{}},
+	}
+
+	for _, c := range cases {
+		if got := bot.SuggestCommands(c.input, c.userID); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("SuggestCommands(%q, %q) = %q, expected %q", c.input, c.userID, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package sapphire
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A single argument in a command's content.
+type token struct {
+	value  string // The argument with the quotes and escapes removed.
+	start  int    // Offset of the start of the argument in the content.
+	end    int    // Offset of the end of the argument in the content.
+	quoted bool   // Whether the argument was quoted.
+}
+
+// The closing quotes for every opening quote, same as the ones the flags regexp understands.
+var quotes = map[rune][]rune{
+	'"':  {'"'},
+	'\'': {'\''},
+	'“':  {'“', '”'},
+	'”':  {'“', '”'},
+	'‘':  {'‘', '’'},
+	'’':  {'‘', '’'},
+}
+
+// Tokenize splits content into arguments, any whitespace (including newlines) separates arguments.
+// Arguments can be quoted with double, single or smart quotes to include whitespace in them: "hello world"
+// A backslash escapes a quote, a whitespace or another backslash: hello\ world
+// Quotes only start an argument at the beginning of it so words like don't are left alone.
+// The only exception is the value of a flag: --reason="hello world"
+func Tokenize(content string) []string {
+	tokens := tokenize(content)
+	args := make([]string, len(tokens))
+	for i, tok := range tokens {
+		args[i] = tok.value
+	}
+	return args
+}
+
+func tokenize(content string) []token {
+	var tokens []token
+	i := 0
+	for i < len(content) {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+		tok := token{start: i}
+		var value strings.Builder
+
+		// Quoted argument, only if the quote is closed otherwise the quote is just a character.
+		if closing, ok := quotes[r]; ok {
+			if end, unquoted := readQuoted(content[i+size:], closing); end >= 0 {
+				value.WriteString(unquoted)
+				tok.quoted = true
+				i += size + end
+			}
+		}
+
+		// Read until the next whitespace, this is the whole argument if it isn't quoted.
+		for i < len(content) {
+			r, size = utf8.DecodeRuneInString(content[i:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			if r == '\\' && i+size < len(content) {
+				next, nextSize := utf8.DecodeRuneInString(content[i+size:])
+				if escapable(next) {
+					value.WriteRune(next)
+					i += size + nextSize
+					continue
+				}
+			}
+			value.WriteRune(r)
+			i += size
+			// Flag values can be quoted too, e.g --reason="hello world"
+			if r == '=' && isFlagStart(value.String()) && strings.Count(value.String(), "=") == 1 && i < len(content) {
+				q, qsize := utf8.DecodeRuneInString(content[i:])
+				if closing, ok := quotes[q]; ok {
+					if end, unquoted := readQuoted(content[i+qsize:], closing); end >= 0 {
+						value.WriteString(unquoted)
+						i += qsize + end
+					}
+				}
+			}
+		}
+
+		tok.end = i
+		tok.value = value.String()
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// Reads a quoted string until one of the closing quotes, returns the offset after the closing quote
+// and the contents with the escapes removed, the offset is -1 if the quote is never closed.
+func readQuoted(content string, closing []rune) (int, string) {
+	var value strings.Builder
+	i := 0
+	for i < len(content) {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if r == '\\' && i+size < len(content) {
+			next, nextSize := utf8.DecodeRuneInString(content[i+size:])
+			if escapable(next) {
+				value.WriteRune(next)
+				i += size + nextSize
+				continue
+			}
+		}
+		for _, c := range closing {
+			if r == c {
+				return i + size, value.String()
+			}
+		}
+		value.WriteRune(r)
+		i += size
+	}
+	return -1, ""
+}
+
+// Only these characters can be escaped, other backslashes are kept as is since they are common in discord. e.g \o/
+func escapable(r rune) bool {
+	_, quote := quotes[r]
+	return quote || r == '\\' || unicode.IsSpace(r)
+}
+
+// Whether an unquoted argument starts like a flag, e.g --name or —name
+func isFlagStart(value string) bool {
+	return strings.HasPrefix(value, "-") || strings.HasPrefix(value, "—")
+}
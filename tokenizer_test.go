@@ -0,0 +1,64 @@
+package sapphire
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := map[string][]string{
+		`create "hello world" content`: {"create", "hello world", "content"},
+		`say 'single quotes' ok`:       {"say", "single quotes", "ok"},
+		"smart “quoted words” ‘too’":   {"smart", "quoted words", "too"},
+		`escaped\ space \"quote\" \\`:  {"escaped space", `"quote"`, `\`},
+		"line\none\n\ntwo":             {"line", "one", "two"},
+		`don't split apostrophes`:      {"don't", "split", "apostrophes"},
+		`"unclosed quote`:              {`"unclosed`, "quote"},
+		`keep \o/ backslashes`:         {"keep", `\o/`, "backslashes"},
+		`"escaped \" inside" "" after`: {`escaped " inside`, "", "after"},
+		"   lots    of   spaces   ":    {"lots", "of", "spaces"},
+		`--reason="quoted flag" after`: {"--reason=quoted flag", "after"},
+	}
+
+	for input, expected := range cases {
+		if got := Tokenize(input); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestJoinedArgsKeepsSpacing(t *testing.T) {
+	content := "\"my tag\" first line\n  second line"
+	tokens := tokenize(content)
+	ctx := &CommandContext{RawArgs: Tokenize(content), argContent: content, argTokens: tokens}
+
+	if ctx.Arg(0).provided || ctx.RawArgs[0] != "my tag" {
+		t.Errorf("Expected the first raw argument to be unquoted, got %q", ctx.RawArgs[0])
+	}
+
+	if joined := ctx.JoinedArgs(1); joined != "first line\n  second line" {
+		t.Errorf("Expected JoinedArgs to keep the original spacing, got %q", joined)
+	}
+
+	if joined := ctx.JoinedArgs(len(ctx.RawArgs)); joined != "" {
+		t.Errorf("Expected JoinedArgs past the end to be empty, got %q", joined)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	cases := []struct {
+		content string
+		rest    string
+		flags   map[string]string
+	}{
+		{`"use --force carefully" now`, `"use --force carefully" now`, map[string]string{}},
+		{`hello --mode="a b" —loud world`, `hello world`, map[string]string{"mode": "a b", "loud": "loud"}},
+		{`don't --x=1`, `don't --x=1`, map[string]string{}},
+	}
+	for _, c := range cases {
+		rest, flags := parseFlags(c.content)
+		if rest != c.rest || !reflect.DeepEqual(flags, c.flags) {
+			t.Errorf("parseFlags(%q) = %q, %v expected %q, %v", c.content, rest, flags, c.rest, c.flags)
+		}
+	}
+}
@@ -3,65 +3,98 @@ package sapphire
 import (
 	"errors"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 type UsageTag struct {
-	Name     string // Name of the tag, e.g for <reason:string> the name is reason.
-	Type     string // Type of the tag, e.g for <reason:string> the type is string.
-	Rest     bool   // If this is rest of the arguments, e.g for <reason:string...> it is true.
-	Required bool   // If this argument is required, e.g <name> is required but [name] is not.
+	Name     string   // Name of the tag, e.g for <reason:string> the name is reason.
+	Type     string   // Type of the tag, e.g for <reason:string> the type is string.
+	Rest     bool     // If this is rest of the arguments, e.g for <reason:string...> it is true.
+	Required bool     // If this argument is required, e.g <name> is required but [name] is not.
+	Min      *float64 // The minimum value of numbers or length of strings, e.g for <amount:int{1,100}> it is 1, nil if there is no minimum.
+	Max      *float64 // The maximum value of numbers or length of strings, e.g for <amount:int{1,100}> it is 100, nil if there is no maximum.
+	Default  string   // The raw value used when the argument isn't provided, e.g for [days:int=7] it is 7.
+	Choices  []string // The values allowed for this argument, e.g for <mode:on|off> it is on and off.
+	Types    []string // The types of a union tried in order, e.g for <target:member|user> it is member and user.
 }
 
+// Returns true if the tag's value can span multiple arguments, see multiWordTypes.
+func (tag *UsageTag) multiWord() bool {
+	if multiWordTypes[tag.Type] {
+		return true
+	}
+	for _, typ := range tag.Types {
+		if multiWordTypes[typ] {
+			return true
+		}
+	}
+	return false
+}
+
+// What ParseUsage is currently reading inside a tag.
+const (
+	usageName = iota
+	usageType
+	usageConstraint
+	usageDefault
+)
+
 // Parse a usage string into tags.
+// Besides the name and type a tag can have constraints and a default value:
+// <amount:int{1,100}> limits the value of numbers between 1 and 100.
+// <name:string{3,32}> limits the length of strings between 3 and 32, {3,} and {,32} leave one side open and {5} means exactly 5.
+// [days:int=7] uses 7 when the argument isn't provided, only optional arguments can have a default.
+// <mode:on|off> only allows one of the values on or off and <add|remove> is a literal that is either add or remove.
+// <target:member|user> is a union, it is a member if it can be parsed as one otherwise a user.
 func ParseUsage(usage string) ([]*UsageTag, error) {
 	// TODO: We'll need to handle more cases to improve error handling.
 	tags := make([]*UsageTag, 0)
 	// The current tag we are parsing and building.
 	current := &UsageTag{Required: false, Rest: false, Type: "", Name: ""}
-	// true if we are currently parsing the type, otherwise the name.
-	typeMode := false
+	// What we are currently parsing, the name, the type, the constraint or the default.
+	mode := usageName
+	constraint := ""
 	for _, c := range usage {
+		// Defaults and constraints are taken as is until they end.
+		if mode == usageDefault && c != '>' && c != ']' {
+			current.Default += string(c)
+			continue
+		}
+		if mode == usageConstraint && c != '}' {
+			constraint += string(c)
+			continue
+		}
 		if c == '<' {
 			current.Required = true
-		} else if c == '>' {
-			// Beginning the tag name with @@ is a syntactic sugar for member and beginning with @ is for user.
-			// And if the type is missing we assume literal.
-			if strings.HasPrefix(current.Name, "@@") && current.Type == "" {
-				current.Name = strings.TrimPrefix(current.Name, "@@")
-				current.Type = "member"
-			} else if strings.HasPrefix(current.Name, "@") && current.Type == "" {
-				current.Name = strings.TrimPrefix(current.Name, "@")
-				current.Type = "user"
-			} else if current.Type == "" {
-				current.Type = "literal"
+		} else if c == '>' || c == ']' {
+			if err := finishUsageTag(current, constraint); err != nil {
+				return tags, err
 			}
 			tags = append(tags, current)
 			current = &UsageTag{Required: false, Rest: false, Type: "", Name: ""}
-			typeMode = false
+			mode = usageName
+			constraint = ""
 		} else if c == '[' {
 			if current.Required {
 				return tags, errors.New("Cannot open an optional tag after opening a required one.")
 			}
-		} else if c == ']' {
-			if strings.HasPrefix(current.Name, "@@") && current.Type == "" {
-				current.Name = strings.TrimPrefix(current.Name, "@@")
-				current.Type = "member"
-			} else if strings.HasPrefix(current.Name, "@") && current.Type == "" {
-				current.Name = strings.TrimPrefix(current.Name, "@")
-				current.Type = "user"
-			} else if current.Type == "" {
-				current.Type = "literal"
-			}
-			tags = append(tags, current)
-			current = &UsageTag{Required: false, Rest: false, Type: "", Name: ""}
-			typeMode = false
 		} else if c == ' ' {
 			continue
 		} else if c == ':' {
-			typeMode = true
+			mode = usageType
+		} else if c == '{' {
+			if constraint != "" {
+				return tags, errors.New("A tag can only have one constraint.")
+			}
+			mode = usageConstraint
+		} else if c == '}' {
+			// Continue with the type so a rest suffix after the constraint still works. e.g <words:string{1,10}...>
+			mode = usageType
+		} else if c == '=' {
+			mode = usageDefault
 		} else {
-			if typeMode {
+			if mode == usageType {
 				current.Type += string(c)
 			} else {
 				current.Name += string(c)
@@ -70,22 +103,111 @@ func ParseUsage(usage string) ([]*UsageTag, error) {
 	}
 	// Now that we know enough about the tags and how many are there we can validate rest args.
 	for i, tag := range tags {
-		if strings.HasSuffix(tag.Type, "...") {
-			if i != len(tags)-1 {
-				return tags, errors.New("Rest parameters can only appear last.")
-			}
-			tag.Type = strings.TrimSuffix(tag.Type, "...")
-			tag.Rest = true
+		if tag.Rest && i != len(tags)-1 {
+			return tags, errors.New("Rest parameters can only appear last.")
 		}
 	}
 	return tags, nil
 }
 
+// Fills in the rest of a tag once it is closed, from it's sugar, choices, constraint and rest suffix.
+func finishUsageTag(tag *UsageTag, constraint string) error {
+	if strings.HasSuffix(tag.Type, "...") {
+		tag.Type = strings.TrimSuffix(tag.Type, "...")
+		tag.Rest = true
+	}
+	// Beginning the tag name with @@ is a syntactic sugar for member and beginning with @ is for user.
+	// Alternatives in the type are choices of strings and alternatives in the name are choices of literals.
+	// And if the type is missing we assume literal.
+	if strings.HasPrefix(tag.Name, "@@") && tag.Type == "" {
+		tag.Name = strings.TrimPrefix(tag.Name, "@@")
+		tag.Type = "member"
+	} else if strings.HasPrefix(tag.Name, "@") && tag.Type == "" {
+		tag.Name = strings.TrimPrefix(tag.Name, "@")
+		tag.Type = "user"
+	} else if strings.Contains(tag.Type, "|") {
+		// If every alternative is a builtin type it's a union, otherwise they are choices.
+		// Unions of custom types are recognized when the command is added to the bot.
+		alternatives := strings.Split(tag.Type, "|")
+		union := true
+		for _, alternative := range alternatives {
+			if _, ok := builtinTypeNames[alternative]; !ok {
+				union = false
+			}
+		}
+		if union {
+			tag.Types = alternatives
+		} else {
+			tag.Choices = alternatives
+			tag.Type = "string"
+		}
+	} else if tag.Type == "" {
+		if strings.Contains(tag.Name, "|") {
+			tag.Choices = strings.Split(tag.Name, "|")
+		}
+		tag.Type = "literal"
+	}
+	for _, choice := range tag.Choices {
+		if choice == "" {
+			return errors.New("Choices cannot be empty.")
+		}
+	}
+
+	tag.Default = strings.TrimSpace(tag.Default)
+	if tag.Default != "" && tag.Required {
+		return errors.New("Only optional arguments can have a default value.")
+	}
+
+	if constraint == "" {
+		return nil
+	}
+	parts := strings.Split(constraint, ",")
+	if len(parts) > 2 {
+		return errors.New("Constraints must be in the form {min,max}.")
+	}
+	bounds := make([]*float64, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return errors.New("Constraints must be numbers.")
+		}
+		bounds[i] = &v
+	}
+	// {5} is exactly 5.
+	tag.Min = bounds[0]
+	tag.Max = bounds[len(bounds)-1]
+	if tag.Min != nil && tag.Max != nil && *tag.Min > *tag.Max {
+		return errors.New("The minimum of a constraint cannot be bigger than the maximum.")
+	}
+	return nil
+}
+
 // HumanizeUsageRegex is the regexp used for HuamnizeUsage
-var HumanizeUsageRegex = regexp.MustCompile("(<|\\[)(\\w+):[^.]+?(\\.\\.\\.)?(>|\\])")
+var HumanizeUsageRegex = regexp.MustCompile("(<|\\[)[^<>\\[\\]]*:[^<>\\[\\]]*(>|\\])")
 
-// HumanizeUsage removes the unneccessary types and shows only the names.
-// e.g <hello:string> <user:user> [rest:int...] => <hello> <user> [rest...]
+// HumanizeUsage removes the unneccessary types and shows only the names, constraints are left out too.
+// e.g <hello:string> <user:user> [rest:int...] [days:int{1,7}=7] <mode:on|off> => <hello> <user> [rest...] [days=7] <on|off>
 func HumanizeUsage(usage string) string {
-	return HumanizeUsageRegex.ReplaceAllString(usage, "$1$2$3$4")
+	return HumanizeUsageRegex.ReplaceAllStringFunc(usage, func(m string) string {
+		tags, err := ParseUsage(m)
+		if err != nil || len(tags) != 1 {
+			return m
+		}
+		tag := tags[0]
+		name := tag.Name
+		if tag.Type == "string" && len(tag.Choices) > 0 {
+			name = strings.Join(tag.Choices, "|")
+		}
+		if tag.Rest {
+			name += "..."
+		}
+		if tag.Default != "" {
+			name += "=" + tag.Default
+		}
+		return m[:1] + name + m[len(m)-1:]
+	})
 }
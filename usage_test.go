@@ -38,3 +38,43 @@ func TestParseUsage(t *testing.T) {
 		t.Errorf("Expected HumanizeUsage(\"%s\") to return \"%s\" but got \"%s\"", tag, expect, res)
 	}
 }
+
+func TestParseUsageConstraints(t *testing.T) {
+	tags, err := ParseUsage("<amount:int{1,100}> <name:string{3,}> [days:int=7] <mode:on|off> [add|remove] [reason:string=no reason given] [words:string{,10}...]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags[0].Type != "int" || *tags[0].Min != 1 || *tags[0].Max != 100 {
+		t.Errorf("Expected amount to be an int between 1 and 100 but got %+v", tags[0])
+	}
+	if *tags[1].Min != 3 || tags[1].Max != nil {
+		t.Errorf("Expected name to only have a minimum but got %+v", tags[1])
+	}
+	if tags[2].Default != "7" || tags[2].Required {
+		t.Errorf("Expected days to default to 7 but got %+v", tags[2])
+	}
+	if tags[3].Type != "string" || fmt.Sprint(tags[3].Choices) != "[on off]" {
+		t.Errorf("Expected mode to be a string choice but got %+v", tags[3])
+	}
+	if tags[4].Type != "literal" || fmt.Sprint(tags[4].Choices) != "[add remove]" {
+		t.Errorf("Expected add|remove to be a literal choice but got %+v", tags[4])
+	}
+	if tags[5].Default != "no reason given" {
+		t.Errorf("Expected the default to keep it's spaces but got %q", tags[5].Default)
+	}
+	if !tags[6].Rest || tags[6].Type != "string" || *tags[6].Max != 10 {
+		t.Errorf("Expected words to be a rest string with a maximum but got %+v", tags[6])
+	}
+
+	for _, usage := range []string{"<days:int=7>", "<amount:int{a,b}>", "<amount:int{5,1}>", "<amount:int{1,2,3}>", "<mode:on|>"} {
+		if _, err := ParseUsage(usage); err == nil {
+			t.Errorf("Expected ParseUsage(\"%s\") to fail", usage)
+		}
+	}
+
+	tag := "<amount:int{1,100}> [days:int{1,7}=7] <mode:on|off> <@user> [words:string...]"
+	expect := "<amount> [days=7] <on|off> <@user> [words...]"
+	if res := HumanizeUsage(tag); res != expect {
+		t.Errorf("Expected HumanizeUsage(\"%s\") to return \"%s\" but got \"%s\"", tag, expect, res)
+	}
+}
@@ -1,6 +1,7 @@
 package sapphire
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -15,3 +16,32 @@ func TestEscape(t *testing.T) {
 		t.Error("Escape didn't return the expectd output for @here")
 	}
 }
+
+func TestMatchNames(t *testing.T) {
+	items := []*nameMatch{
+		{names: []string{"general"}, display: "general"},
+		{names: []string{"general-2"}, display: "general-2"},
+		{names: []string{"off-topic"}, display: "off-topic"},
+		{names: []string{"Moderator", "Mod#0001"}, display: "Moderator"},
+	}
+	check := func(query string, expected ...string) {
+		matches := matchNames(query, items)
+		got := make([]string, len(matches))
+		for i, match := range matches {
+			got[i] = match.display
+		}
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("matchNames(%q) = %v, expected %v", query, got, expected)
+		}
+	}
+	check("general", "general")
+	check("gen", "general", "general-2")
+	check("topic", "off-topic")
+	check("mod#0001", "Moderator")
+	check("genral", "general")
+	check("nothing")
+
+	if d := levenshtein("kitten", "sitting"); d != 3 {
+		t.Errorf("Expected the distance between kitten and sitting to be 3 but got %d", d)
+	}
+}